@@ -24,31 +24,70 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/jhonnyV-V/phoemux/core"
 	"github.com/spf13/cobra"
 )
 
+var (
+	createTemplate      string
+	createWindows       []string
+	createDefaultWindow string
+	createPath          string
+	createNoEdit        bool
+)
+
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "create a new ash",
 	Long: `create command.
 creates a new ash under the $XDG_CONFIG_HOME/phoemux directory:
-phoemux create <project_name>`,
+phoemux create <project_name>
+
+the windows come from a template, by default it is detected from the project files
+(node, go, python or rails), templates in $XDG_CONFIG_HOME/phoemux/templates/<name>.yaml
+can be used or override the built-in ones:
+phoemux create <project_name> --template <name>
+
+to create it from scripts without opening the editor:
+phoemux create <project_name> --no-edit --window code:"nvim ." --window server:"make run" --default-window code`,
 	Args:    cobra.MinimumNArgs(1),
-	Example: "phoemux create <project_name>",
+	Example: "phoemux create <project_name>\nphoemux create <project_name> --template go\nphoemux create <project_name> --no-edit --path ~/projects/api --window code:\"nvim .\"",
 	Run: func(cmd *cobra.Command, args []string) {
 		pwd, err := os.Getwd()
 		if err != nil {
 			fmt.Printf("failed to get pwd: %s\n", err)
 			os.Exit(1)
 		}
+		if createPath != "" {
+			pwd, err = filepath.Abs(createPath)
+			exitOnError(err)
+		}
+		windows, err := core.ParseWindows(createWindows)
+		exitOnError(err)
+		if createNoEdit {
+			core.OpenEditor = false
+		}
+
 		phoemuxConfigPath := core.CreateConfigDir()
-		core.Create(phoemuxConfigPath, pwd, args[0])
+		exitOnError(core.Create(phoemuxConfigPath, pwd, args[0], core.CreateOptions{
+			Template:      createTemplate,
+			Windows:       windows,
+			DefaultWindow: createDefaultWindow,
+		}))
 	},
 }
 
 func init() {
+	createCmd.Flags().StringVarP(&createTemplate, "template", "t", "", "template of the ash, detected from the project files by default")
+	createCmd.Flags().StringArrayVarP(&createWindows, "window", "w", nil, "window as name:command, replaces the windows of the template, repeat a name to split it")
+	createCmd.Flags().StringVarP(&createDefaultWindow, "default-window", "d", "", "window selected when the session is opened")
+	createCmd.Flags().StringVarP(&createPath, "path", "p", "", "directory of the ash, the current one by default")
+	createCmd.Flags().BoolVarP(&createNoEdit, "no-edit", "n", false, "do not open the ash in the editor, it is validated instead")
+	createCmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return core.ListTemplates(core.GetConfigPath()), cobra.ShellCompDirectiveNoFileComp
+	})
 	rootCmd.AddCommand(createCmd)
 }
@@ -38,7 +38,7 @@ phoemux delete <project_name>`,
 	Run: func(cmd *cobra.Command, args []string) {
 		phoemuxConfigPath := core.GetConfigPath()
 
-		core.Delete(phoemuxConfigPath, args[0])
+		exitOnError(core.Delete(phoemuxConfigPath, args[0]))
 	},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		phoemuxConfigPath := core.GetConfigPath()
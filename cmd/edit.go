@@ -38,7 +38,7 @@ phoemux edit <project_name>`,
 	Example: "phoemux edit <project_name>",
 	Run: func(cmd *cobra.Command, args []string) {
 		phoemuxConfigPath := core.GetConfigPath()
-		core.Edit(phoemuxConfigPath, args[0])
+		exitOnError(core.Edit(phoemuxConfigPath, args[0]))
 	},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		phoemuxConfigPath := core.GetConfigPath()
@@ -0,0 +1,68 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export an ash to a shell script",
+	Long: `export command.
+prints a POSIX shell script that runs the same tmux commands phoemux runs to open the ash,
+it can be shared with people without phoemux or used to review what an ash does:
+phoemux export <project_name> --format sh`,
+	Args:    cobra.MinimumNArgs(1),
+	Example: "phoemux export <project_name> > workspace.sh",
+	Run: func(cmd *cobra.Command, args []string) {
+		phoemuxConfigPath := core.GetConfigPath()
+		script, err := core.Export(phoemuxConfigPath, args[0], exportFormat)
+		exitOnError(err)
+		fmt.Print(script)
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		phoemuxConfigPath := core.GetConfigPath()
+
+		ashes, err := core.GetSimpleList(phoemuxConfigPath)
+
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return ashes, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "sh", "format of the export: sh")
+	exportCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return core.ExportFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/jhonnyV-V/phoemux/tmux"
+	"github.com/spf13/cobra"
+)
+
+var freezeSession string
+
+// freezeCmd represents the freeze command
+var freezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "create an ash from a running tmux session",
+	Long: `freeze command.
+creates a new ash with the windows, layouts, paths and commands of a running tmux session,
+by default the current one:
+phoemux freeze <project_name> [--session session_name]`,
+	Args:    cobra.MinimumNArgs(1),
+	Example: "phoemux freeze <project_name>\nphoemux freeze <project_name> --session <session_name>",
+	Run: func(cmd *cobra.Command, args []string) {
+		if freezeSession == "" {
+			if !tmux.IsInsideTmux() {
+				exitOnError(fmt.Errorf("You are not in a tmux session, use --session to choose one"))
+			}
+			freezeSession = tmux.GetCurrentSessionName()
+		}
+
+		phoemuxConfigPath := core.CreateConfigDir()
+		exitOnError(core.Freeze(phoemuxConfigPath, args[0], freezeSession))
+		fmt.Printf("session %s frozen into %s\n", freezeSession, args[0])
+	},
+}
+
+func init() {
+	freezeCmd.Flags().StringVarP(&freezeSession, "session", "s", "", "session to freeze")
+	freezeCmd.RegisterFlagCompletionFunc("session", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return tmux.GetListOfSessions(), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(freezeCmd)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/spf13/cobra"
+)
+
+var clearHistory bool
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "list the recently opened ashes",
+	Long: `history command.
+lists the opened ashes from the most recent one with how many times they were opened,
+outside of tmux the position is the one used by last -n:
+phoemux history`,
+	Args:    cobra.NoArgs,
+	Example: "phoemux history\nphoemux history --clear",
+	Run: func(cmd *cobra.Command, args []string) {
+		phoemuxConfigPath := core.GetConfigPath()
+
+		if clearHistory {
+			exitOnError(core.ClearHistory(phoemuxConfigPath))
+			return
+		}
+
+		entries, err := core.History(phoemuxConfigPath)
+		exitOnError(err)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "#\tASH\tSESSION\tOPENED\tLAST OPENED\n")
+		for i, entry := range entries {
+			alias := entry.Alias
+			if filepath.IsAbs(alias) {
+				alias = filepath.Base(filepath.Dir(alias)) + "/" + core.LocalAshName
+			}
+			lastOpened := "-"
+			if !entry.LastOpened.IsZero() {
+				lastOpened = entry.LastOpened.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", i+1, alias, entry.SessionName, entry.Count, lastOpened)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	historyCmd.Flags().BoolVar(&clearHistory, "clear", false, "forget every opened ash")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/spf13/cobra"
+)
+
+var importFormat string
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "create an ash from a tmuxinator or tmuxp project",
+	Long: `import command.
+converts a tmuxinator or tmuxp project file into a new ash,
+by default the ash is named after the session of the project:
+phoemux import --from tmuxinator|tmuxp <file> [project_name]`,
+	Args:    cobra.RangeArgs(1, 2),
+	Example: "phoemux import --from tmuxinator ~/.config/tmuxinator/blog.yml\nphoemux import --from tmuxp ~/.tmuxp/api.json api",
+	Run: func(cmd *cobra.Command, args []string) {
+		alias := ""
+		if len(args) > 1 {
+			alias = args[1]
+		}
+
+		phoemuxConfigPath := core.CreateConfigDir()
+		alias, err := core.Import(phoemuxConfigPath, importFormat, args[0], alias)
+		exitOnError(err)
+		fmt.Printf("%s imported as %s\n", args[0], alias)
+
+		err = core.Validate(phoemuxConfigPath, alias)
+		if err != nil {
+			fmt.Printf("the ash needs some changes before it can be opened, use phoemux edit %s\n%s\n", alias, err)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importFormat, "from", "f", "", "format of the project file: tmuxinator or tmuxp")
+	importCmd.MarkFlagRequired("from")
+	importCmd.RegisterFlagCompletionFunc("from", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return core.ImportFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(importCmd)
+}
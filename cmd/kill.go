@@ -24,6 +24,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/jhonnyV-V/phoemux/core"
 	"github.com/jhonnyV-V/phoemux/tmux"
 	"github.com/spf13/cobra"
 )
@@ -43,17 +44,17 @@ Kills current tmux session using tmux kill-session:
 phoemux kill`,
 	Example: "phoemux run kill -t react-app -a server-app",
 	Run: func(cmd *cobra.Command, args []string) {
+		phoemuxConfigPath := core.GetConfigPath()
 		tmuxEnvExist := tmux.IsInsideTmux()
 		if !tmuxEnvExist && target == "" {
-			fmt.Printf("You are not in a tmux session\n")
-			return
+			exitOnError(fmt.Errorf("You are not in a tmux session"))
 		}
 
 		if !dumb_attach && attach == "" {
 			if (target == "") {
 				target = tmux.GetCurrentSessionName()
 			}
-			tmux.Kill(target)
+			exitOnError(core.Kill(phoemuxConfigPath, target))
 			return
 		}
 
@@ -62,24 +63,21 @@ phoemux kill`,
 		}
 
 		if dumb_attach {
-			ash := tmux.Ash{}
+			sessionName := ""
 			if tmuxEnvExist {
-				ash.SessionName = tmux.GetOtherSession()
-				if ash.SessionName == "" {
-					fmt.Printf("can't find other tmux session\n")
-					return
+				sessionName = tmux.GetOtherSession()
+				if sessionName == "" {
+					exitOnError(fmt.Errorf("can't find other tmux session"))
 				}
 			}
-			tmux.ChangeSession(ash)
-			tmux.Kill(target)
+			exitOnError(core.Attach(phoemuxConfigPath, sessionName))
+			exitOnError(core.Kill(phoemuxConfigPath, target))
 			return
 		}
 
 		if attach != "" {
-			tmux.ChangeSession(tmux.Ash{
-				SessionName: attach,
-			})
-			tmux.Kill(target)
+			exitOnError(core.Attach(phoemuxConfigPath, attach))
+			exitOnError(core.Kill(phoemuxConfigPath, target))
 			return
 		}
 	},
@@ -26,21 +26,34 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var lastN int
+
 // lastCmd represents the last command
 var lastCmd = &cobra.Command{
 	Use:   "last",
 	Short: "reopens last opened project",
 	Long: `last command.
-Opens the last opened Ash:
-phoemux last`,
+Opens the last opened Ash, inside tmux the ash of the current session is skipped
+so last toggles between the two most recent ashes:
+phoemux last
+to go further back in the history:
+phoemux last -n 2
+to see what it would run without running it:
+phoemux last --dry-run`,
 	Run: func(cmd *cobra.Command, args []string) {
 		phoemuxConfigPath := core.GetConfigPath()
-		core.OpenFromCache(phoemuxConfigPath)
+		if dryRun {
+			printPlan(core.PlanLast(phoemuxConfigPath, lastN))
+			return
+		}
+		exitOnError(core.OpenLast(phoemuxConfigPath, lastN))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(lastCmd)
+	lastCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the commands that would run without running them")
+	lastCmd.Flags().IntVarP(&lastN, "nth", "n", 1, "open the nth most recent ash, 2 goes back one more")
 
 	// Here you will define your flags and configuration settings.
 
@@ -36,7 +36,7 @@ phoemux list`,
 	Run: func(cmd *cobra.Command, args []string) {
 		phoemuxConfigPath := core.GetConfigPath()
 
-		core.ListAshes(phoemuxConfigPath)
+		exitOnError(core.ListAshes(phoemuxConfigPath))
 	},
 }
 
@@ -0,0 +1,65 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/spf13/cobra"
+)
+
+var renameSession bool
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "rename an existing ash",
+	Long: `rename command.
+moves an ash to a new alias, the session name is updated when it was the alias
+and the ashes extending it and last keep working:
+phoemux rename <project_name> <new_project_name>
+use --session to also rename the running tmux session of the ash`,
+	Args:    cobra.ExactArgs(2),
+	Example: "phoemux rename <project_name> <new_project_name>\nphoemux rename <project_name> <new_project_name> --session",
+	Run: func(cmd *cobra.Command, args []string) {
+		phoemuxConfigPath := core.GetConfigPath()
+		exitOnError(core.Rename(phoemuxConfigPath, args[0], args[1], renameSession))
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		phoemuxConfigPath := core.GetConfigPath()
+
+		ashes, err := core.GetSimpleList(phoemuxConfigPath)
+
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return ashes, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	renameCmd.Flags().BoolVarP(&renameSession, "session", "s", false, "rename the running tmux session too")
+	rootCmd.AddCommand(renameCmd)
+}
@@ -22,12 +22,20 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/jhonnyV-V/phoemux/tmux"
 	"github.com/spf13/cobra"
 )
 
+var (
+	dryRun        bool
+	traceCommands bool
+	setVars       []string
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "phoemux",
@@ -40,12 +48,39 @@ to create one go to the directory of the project and use:
 phoemux create <project_name>
 
 to open it just run:
-phoemux <project_name>`,
-	Args:    cobra.MinimumNArgs(1),
-	Example: "phoemux <project_name>\nphoemux <command>",
+phoemux <project_name>
+
+without a project name the .phoemux.yaml of the current directory
+or its closest parent is opened, so it can be committed along with the code`,
+	Args:    cobra.MaximumNArgs(1),
+	Example: "phoemux <project_name>\nphoemux\nphoemux <project_name> --set branch=feature-x\nphoemux <command>",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if traceCommands {
+			tmux.Trace = os.Stderr
+		}
+		for _, assignment := range setVars {
+			key, value, err := core.ParseVar(assignment)
+			exitOnError(err)
+			core.Vars[key] = value
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		phoemuxConfigPath := core.GetConfigPath()
-		core.Open(phoemuxConfigPath, args[0])
+		if len(args) == 0 {
+			localAsh := findLocalAsh()
+			if dryRun {
+				printPlan(core.PlanLocal(phoemuxConfigPath, localAsh))
+				return
+			}
+			exitOnError(core.OpenLocal(phoemuxConfigPath, localAsh))
+			return
+		}
+
+		if dryRun {
+			printPlan(core.Plan(phoemuxConfigPath, args[0]))
+			return
+		}
+		exitOnError(core.Open(phoemuxConfigPath, args[0]))
 	},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		phoemuxConfigPath := core.GetConfigPath()
@@ -69,7 +104,37 @@ func Execute() {
 	}
 }
 
+// exitOnError reports the error and exits with a non-zero code so
+// scripts wrapping phoemux can detect the failure
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
+
+// findLocalAsh returns the .phoemux.yaml of the current directory or its parents
+func findLocalAsh() string {
+	pwd, err := os.Getwd()
+	exitOnError(err)
+	localAsh, err := core.FindLocalAsh(pwd)
+	exitOnError(err)
+	return localAsh
+}
+
+// printPlan prints the commands that would run, one per line
+func printPlan(plan []string, err error) {
+	exitOnError(err)
+	for _, command := range plan {
+		fmt.Println(command)
+	}
+}
+
 func init() {
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the commands that would run without running them")
+	rootCmd.PersistentFlags().StringArrayVar(&setVars, "set", nil, "set a variable of the ash as key=value, can be repeated")
+	rootCmd.PersistentFlags().BoolVar(&traceCommands, "trace", false, "print every tmux command to stderr before running it")
 }
 
 func SetVersion(version string) {
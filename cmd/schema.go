@@ -0,0 +1,62 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/spf13/cobra"
+)
+
+var writeSchema bool
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "print the JSON Schema of ashes",
+	Long: `schema command.
+prints the JSON Schema of the ash files, editors can use it to autocomplete and lint ashes:
+phoemux schema
+
+with --write the schema is stored in the phoemux config directory,
+where the header of the ashes created by phoemux points to:
+phoemux schema --write`,
+	Example: "phoemux schema > ash.schema.json\nphoemux schema --write",
+	Run: func(cmd *cobra.Command, args []string) {
+		if writeSchema {
+			phoemuxConfigPath := core.CreateConfigDir()
+			exitOnError(core.WriteSchema(phoemuxConfigPath))
+			fmt.Printf("schema written to %s\n", core.SchemaPath(phoemuxConfigPath))
+			return
+		}
+
+		schema, err := core.Schema()
+		exitOnError(err)
+		fmt.Printf("%s\n", schema)
+	},
+}
+
+func init() {
+	schemaCmd.Flags().BoolVarP(&writeSchema, "write", "w", false, "write the schema to the config directory")
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2024 Jhonny Varela jhonny_varela_visbal@hotmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/spf13/cobra"
+)
+
+var validateAll bool
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "check that ashes are valid",
+	Long: `validate command.
+checks that an ash only uses known keys, that its paths exist,
+that every window has a terminal and that the default window exists:
+phoemux validate <project_name>
+phoemux validate --all
+without arguments the .phoemux.yaml of the current directory or its parents is checked`,
+	Args:    cobra.MaximumNArgs(1),
+	Example: "phoemux validate <project_name>\nphoemux validate --all",
+	Run: func(cmd *cobra.Command, args []string) {
+		phoemuxConfigPath := core.GetConfigPath()
+
+		if validateAll {
+			exitOnError(core.ValidateAll(phoemuxConfigPath))
+			fmt.Printf("all ashes are valid\n")
+			return
+		}
+
+		if len(args) == 0 {
+			localAsh := findLocalAsh()
+			exitOnError(core.ValidateLocal(phoemuxConfigPath, localAsh))
+			fmt.Printf("%s is valid\n", localAsh)
+			return
+		}
+
+		exitOnError(core.Validate(phoemuxConfigPath, args[0]))
+		fmt.Printf("%s is valid\n", args[0])
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		phoemuxConfigPath := core.GetConfigPath()
+
+		ashes, err := core.GetSimpleList(phoemuxConfigPath)
+
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return ashes, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	validateCmd.Flags().BoolVarP(&validateAll, "all", "a", false, "validate every ash")
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/goccy/go-yaml"
+	"github.com/muesli/termenv"
+)
+
+const (
+	ConfigFileName = "config.yaml"
+	// configAlias can not be used by an ash, its file would be the config
+	configAlias = "config"
+)
+
+var (
+	keymapValues = []string{"default", "vim"}
+	// defaultKeys are the keys of every action of the list, the navigation ones match bubbles
+	// except for the pages, which leave out d and f for delete and freeze
+	defaultKeys = map[string][]string{
+		"up":        {"up", "k"},
+		"down":      {"down", "j"},
+		"prevPage":  {"left", "h", "pgup", "b", "u"},
+		"nextPage":  {"right", "l", "pgdown"},
+		"goToStart": {"home", "g"},
+		"goToEnd":   {"end", "G"},
+		"filter":    {"/"},
+		"quit":      {"q", "ctrl+c"},
+		"tab":       {"tab"},
+		"open":      {"enter"},
+		"mark":      {"space"},
+		"edit":      {"e"},
+		"delete":    {"d"},
+		"kill":      {"x"},
+		"rename":    {"r"},
+		"freeze":    {"f"},
+		"confirm":   {"y"},
+		"cancel":    {"n"},
+	}
+	// vimKeys replace the default keys that clash with the actions or are not vim-like
+	vimKeys = map[string][]string{
+		"prevPage": {"left", "pgup", "ctrl+u", "ctrl+b"},
+		"nextPage": {"right", "pgdown", "ctrl+d", "ctrl+f"},
+		"open":     {"enter", "l"},
+		"mark":     {"space", "v"},
+		"cancel":   {"n", "esc"},
+	}
+	defaultTheme = Theme{
+		Selected:          "170",
+		Warning:           "220",
+		Keyword:           "204",
+		KeywordBackground: "235",
+		Detail:            "241",
+		Running:           "42",
+		Marked:            "212",
+	}
+)
+
+// Config is the config.yaml of the config dir, it changes the keys and the colors of the list
+type Config struct {
+	// Keymap is the preset the keys start from, default or vim
+	Keymap string `yaml:"keymap,omitempty"`
+	// Keys replace the keys of an action, e.g. delete: [D]
+	Keys  map[string][]string `yaml:"keys,omitempty"`
+	Theme Theme               `yaml:"theme,omitempty"`
+}
+
+// Theme holds the colors of the list as ANSI numbers or hex codes, empty ones keep the default
+type Theme struct {
+	// NoColor drops every color, it is also set by the NO_COLOR environment variable
+	NoColor           bool   `yaml:"noColor,omitempty"`
+	Selected          string `yaml:"selected,omitempty"`
+	Warning           string `yaml:"warning,omitempty"`
+	Keyword           string `yaml:"keyword,omitempty"`
+	KeywordBackground string `yaml:"keywordBackground,omitempty"`
+	Detail            string `yaml:"detail,omitempty"`
+	Running           string `yaml:"running,omitempty"`
+	Marked            string `yaml:"marked,omitempty"`
+}
+
+func ConfigPath(phoemuxConfigPath string) string {
+	return filepath.Join(phoemuxConfigPath, ConfigFileName)
+}
+
+// LoadConfig reads config.yaml, the defaults are used when it does not exist
+func LoadConfig(phoemuxConfigPath string) (Config, error) {
+	var config Config
+
+	filePath := ConfigPath(phoemuxConfigPath)
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	err = yaml.UnmarshalWithOptions(content, &config, yaml.Strict())
+	if err != nil {
+		return config, fmt.Errorf("%s %s", filePath, yaml.FormatError(err, false, true))
+	}
+
+	if config.Keymap != "" && !slices.Contains(keymapValues, config.Keymap) {
+		return config, fmt.Errorf("%s: keymap %s must be one of %s", filePath, config.Keymap, strings.Join(keymapValues, ", "))
+	}
+	for action, keys := range config.Keys {
+		if _, found := defaultKeys[action]; !found {
+			return config, fmt.Errorf("%s: unknown action %s in keys", filePath, action)
+		}
+		if len(keys) == 0 {
+			return config, fmt.Errorf("%s: action %s needs at least one key", filePath, action)
+		}
+	}
+	err = checkKeys(config.keys())
+	if err != nil {
+		return config, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return config, nil
+}
+
+// checkKeys rejects a key bound to more than one action
+func checkKeys(keys map[string][]string) error {
+	bound := map[string]string{}
+	for _, action := range slices.Sorted(maps.Keys(keys)) {
+		for _, k := range keys[action] {
+			if other, found := bound[k]; found && other != action {
+				return fmt.Errorf("key %s is bound to both %s and %s", k, other, action)
+			}
+			bound[k] = action
+		}
+	}
+	return nil
+}
+
+// keys resolves the keys of every action from the keymap and the remapped actions
+func (c Config) keys() map[string][]string {
+	keys := map[string][]string{}
+	for action, actionKeys := range defaultKeys {
+		keys[action] = actionKeys
+	}
+	if c.Keymap == "vim" {
+		for action, actionKeys := range vimKeys {
+			keys[action] = actionKeys
+		}
+	}
+	for action, actionKeys := range c.Keys {
+		keys[action] = actionKeys
+	}
+	return keys
+}
+
+// binding creates the key binding of an action, config.yaml names the space key
+// while bubbletea reports it as a blank
+func binding(keys []string, help string) key.Binding {
+	pressed := []string{}
+	for _, k := range keys {
+		if k == "space" {
+			k = " "
+		}
+		pressed = append(pressed, k)
+	}
+	return key.NewBinding(
+		key.WithKeys(pressed...),
+		key.WithHelp(strings.Join(keys, "/"), help),
+	)
+}
+
+// setListKeys applies the navigation keys that differ from bubbles to the list,
+// the others keep the help of bubbles
+func setListKeys(l *list.Model, keys map[string][]string) {
+	set := func(b *key.Binding, action, help string) {
+		if !slices.Equal(keys[action], b.Keys()) {
+			*b = binding(keys[action], help)
+		}
+	}
+	set(&l.KeyMap.CursorUp, "up", "up")
+	set(&l.KeyMap.CursorDown, "down", "down")
+	set(&l.KeyMap.PrevPage, "prevPage", "prev page")
+	set(&l.KeyMap.NextPage, "nextPage", "next page")
+	set(&l.KeyMap.GoToStart, "goToStart", "go to start")
+	set(&l.KeyMap.GoToEnd, "goToEnd", "go to end")
+	set(&l.KeyMap.Filter, "filter", "filter")
+	if !slices.Equal(keys["quit"], defaultKeys["quit"]) {
+		// esc keeps quitting the list when there is no filter to clear
+		l.KeyMap.Quit = binding(append(slices.Clone(keys["quit"]), "esc"), "quit")
+	}
+}
+
+// noColor tells if the colors are dropped, see https://no-color.org
+func (t Theme) noColor() bool {
+	return t.NoColor || os.Getenv("NO_COLOR") != ""
+}
+
+// setTheme replaces the colors of the styles of the list
+func setTheme(theme Theme) {
+	if theme.noColor() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	color := func(value, fallback string) lipgloss.Color {
+		if value == "" {
+			return lipgloss.Color(fallback)
+		}
+		return lipgloss.Color(value)
+	}
+	selected := color(theme.Selected, defaultTheme.Selected)
+	detail := color(theme.Detail, defaultTheme.Detail)
+
+	selectedItemStyle = selectedItemStyle.Foreground(selected)
+	activeTabStyle = activeTabStyle.Foreground(selected)
+	previewTitleStyle = previewTitleStyle.Foreground(selected)
+	warningTextStyle = warningTextStyle.Foreground(color(theme.Warning, defaultTheme.Warning))
+	keywordStyle = keywordStyle.
+		Foreground(color(theme.Keyword, defaultTheme.Keyword)).
+		Background(color(theme.KeywordBackground, defaultTheme.KeywordBackground))
+	detailStyle = detailStyle.Foreground(detail)
+	tabStyle = tabStyle.Foreground(detail)
+	previewStyle = previewStyle.BorderForeground(detail)
+	runningStyle = runningStyle.Foreground(color(theme.Running, defaultTheme.Running))
+	markedStyle = markedStyle.Foreground(color(theme.Marked, defaultTheme.Marked))
+}
+
+// reservedAlias rejects the alias whose file would be config.yaml
+func reservedAlias(alias string) error {
+	if alias == configAlias {
+		return fmt.Errorf("alias %s is reserved for %s", alias, ConfigFileName)
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/jhonnyV-V/phoemux/tmux"
@@ -17,6 +20,12 @@ import (
 var (
 	OpenEditor = true
 	Choice     = ""
+	// Choices are the ashes marked in the list to be opened together
+	Choices []string
+	// SessionChoice is the running session picked in the sessions tab of the list
+	SessionChoice = ""
+	// Backend runs the tmux operations, tests replace it with a fake
+	Backend tmux.Backend = tmux.Tmux{}
 )
 
 func fileExist(path string) bool {
@@ -33,20 +42,21 @@ func fileExist(path string) bool {
 	return true
 }
 
-func getDefault(path, alias string) string {
-	return fmt.Sprintf(`path: "%s"
-sessionName: "%s"
-defaultWindow: code
-windows:
-- name: code
-  terminals:
-  - command: echo "do something here"
-- name: servers
-  terminals:
-  - command: ls`,
+// schemaHeader lets editors using yaml-language-server autocomplete and lint the ash
+func schemaHeader(phoemuxConfigPath string) string {
+	return fmt.Sprintf(
+		"# yaml-language-server: $schema=%s\n",
+		SchemaPath(phoemuxConfigPath),
+	)
+}
+
+// renderAsh puts the template under the path and session name of the new ash
+func renderAsh(phoemuxConfigPath, path, alias, template string) string {
+	return schemaHeader(phoemuxConfigPath) + fmt.Sprintf(
+		"path: \"%s\"\nsessionName: \"%s\"\n",
 		path,
 		alias,
-	)
+	) + template
 }
 
 func GetConfigPath() string {
@@ -64,7 +74,7 @@ func CreateConfigDir() string {
 	_, err := os.Stat(phoemuxConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			err = os.Mkdir(phoemuxConfigPath, 0766)
+			err = os.MkdirAll(phoemuxConfigPath, 0766)
 			if err != nil {
 				fmt.Printf("failed to create phoenix dir: %s\n", err)
 				os.Exit(3)
@@ -78,65 +88,142 @@ func CreateConfigDir() string {
 	return phoemuxConfigPath
 }
 
-func Create(phoemuxConfigPath, pwd, alias string) {
+// CreateOptions changes the ash written by Create
+type CreateOptions struct {
+	//template of the ash, detected from the files in pwd when empty
+	Template string
+	//windows that replace the ones of the template
+	Windows       []tmux.Window
+	DefaultWindow string
+}
 
-	if alias == "" {
-		fmt.Printf("create command expects an alias\n")
-		return
+// ParseWindows turns name:command specs into windows, specs with
+// the same name add panes to the window
+func ParseWindows(specs []string) ([]tmux.Window, error) {
+	windows := []tmux.Window{}
+	for _, spec := range specs {
+		name, command, _ := strings.Cut(spec, ":")
+		if name == "" {
+			return nil, fmt.Errorf("invalid window %q, expected name:command", spec)
+		}
+		terminal := tmux.Terminal{Command: command}
+
+		i := slices.IndexFunc(windows, func(w tmux.Window) bool {
+			return w.Name == name
+		})
+		if i == -1 {
+			windows = append(windows, tmux.Window{
+				Name:      name,
+				Terminals: []tmux.Terminal{terminal},
+			})
+			continue
+		}
+		windows[i].Terminals = append(windows[i].Terminals, terminal)
 	}
+	return windows, nil
+}
 
-	filePath := fmt.Sprintf(
-		"%s/%s.yaml",
-		phoemuxConfigPath,
-		alias,
-	)
+// createContent renders the template, the windows of the options are applied
+// by decoding it so they only lose the comments of the template when used
+func createContent(phoemuxConfigPath, pwd, alias string, options CreateOptions) (string, error) {
+	body, err := templateBody(phoemuxConfigPath, options.Template)
+	if err != nil {
+		return "", err
+	}
+	if len(options.Windows) == 0 && options.DefaultWindow == "" {
+		return renderAsh(phoemuxConfigPath, pwd, alias, body), nil
+	}
 
-	exist := fileExist(filePath)
+	var ash tmux.Ash
+	err = yaml.Unmarshal([]byte(body), &ash)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", options.Template, err)
+	}
+	ash.Path = pwd
+	ash.SessionName = alias
+	if len(options.Windows) > 0 {
+		ash.Windows = options.Windows
+		ash.DefaultWindow = options.Windows[0].Name
+	}
+	if options.DefaultWindow != "" {
+		ash.DefaultWindow = options.DefaultWindow
+	}
+
+	content, err := yaml.Marshal(ash)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ash: %w", err)
+	}
+	return schemaHeader(phoemuxConfigPath) + string(content), nil
+}
 
-	if exist {
-		fmt.Printf("ash for %s already exist\n", alias)
-		fmt.Printf("if you want to edit it use the edit command\n")
-		return
+// Create writes a new ash and opens it in the editor, without
+// the editor the ash is validated before it is written
+func Create(phoemuxConfigPath, pwd, alias string, options CreateOptions) error {
+	if alias == "" {
+		return fmt.Errorf("create command expects an alias")
 	}
+	if err := reservedAlias(alias); err != nil {
+		return err
+	}
+
+	filePath := ashFilePath(phoemuxConfigPath, alias)
 
-	config, err := os.Create(filePath)
+	if fileExist(filePath) {
+		return fmt.Errorf("ash for %s already exist, if you want to edit it use the edit command", alias)
+	}
+
+	if options.Template == "" {
+		options.Template = DetectTemplate(pwd)
+		if options.Template != DefaultTemplate && len(options.Windows) == 0 {
+			fmt.Printf("using the %s template\n", options.Template)
+		}
+	}
+	example, err := createContent(phoemuxConfigPath, pwd, alias, options)
 	if err != nil {
-		fmt.Printf("Failed to create ash: %s\n", err)
-		return
+		return err
 	}
 
-	example := getDefault(pwd, alias)
+	if !OpenEditor {
+		_, err = parseAsh(phoemuxConfigPath, filePath, "", []byte(example))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = WriteSchema(phoemuxConfigPath)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
 
-	_, err = config.Write([]byte(example))
+	err = os.WriteFile(filePath, []byte(example), 0666)
 	if err != nil {
-		fmt.Printf("Failed write ash: %s\n", err)
-		return
+		return fmt.Errorf("Failed write ash: %w", err)
 	}
-	config.Close()
 
 	if !OpenEditor {
-		return
+		return nil
 	}
 
+	return runEditor(filePath)
+}
+
+// runEditor opens the file with $EDITOR and waits until it is closed
+func runEditor(filePath string) error {
 	editor := getEditor()
 	cmd := exec.Command("sh", "-c", editor+" "+filePath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
-	err = cmd.Start()
-	if err != nil {
-		fmt.Printf("failed to open editor: %s\n", err)
-	}
-	err = cmd.Wait()
+	err := cmd.Run()
 	if err != nil {
-		fmt.Printf("Error while editing the file: %s\n", err)
+		return fmt.Errorf("Error while editing the file: %w", err)
 	}
+	return nil
 }
 
-func Edit(phoemuxConfigPath, alias string) {
+func Edit(phoemuxConfigPath, alias string) error {
 	if alias == "" {
-		fmt.Printf("create command expects an alias\n")
-		return
+		return fmt.Errorf("edit command expects an alias")
 	}
 
 	filePath := fmt.Sprintf(
@@ -146,24 +233,19 @@ func Edit(phoemuxConfigPath, alias string) {
 	)
 
 	if !fileExist(filePath) {
-		fmt.Printf("Ash %s does not exist\n", alias)
-		return
+		return fmt.Errorf("Ash %s does not exist", alias)
 	}
 
-	editor := getEditor()
-	cmd := exec.Command("sh", "-c", editor+" "+filePath)
-	cmd.Env = nil
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Start()
-	if err != nil {
-		fmt.Printf("failed to open editor: %s\n", err)
-	}
-	err = cmd.Wait()
-	if err != nil {
-		fmt.Printf("Error while editing the file: %s\n", err)
+	return runEditor(filePath)
+}
+
+// ashAlias returns the alias of a file of the config dir, found is false for the files that are not ashes
+func ashAlias(fileName string) (string, bool) {
+	if fileName == ConfigFileName || !strings.Contains(fileName, ".yaml") {
+		return "", false
 	}
+	name, _, _ := strings.Cut(fileName, ".yaml")
+	return name, true
 }
 
 func GetSimpleList(phoemuxConfigPath string) ([]string, error) {
@@ -175,173 +257,342 @@ func GetSimpleList(phoemuxConfigPath string) ([]string, error) {
 	}
 
 	for _, ash := range files {
-		if !strings.Contains(ash.Name(), ".yaml") {
+		name, found := ashAlias(ash.Name())
+		if !found {
 			continue
 		}
-		name, _, _ := strings.Cut(ash.Name(), ".yaml")
 		ashes = append(ashes, name)
 	}
 
 	return ashes, nil
 }
 
-func getListOfItems(ashes []fs.DirEntry) []list.Item {
-	items := []list.Item{}
+func getListOfItems(phoemuxConfigPath string, ashes []fs.DirEntry) []list.Item {
+	names := []string{}
 	for _, ash := range ashes {
-		if !strings.Contains(ash.Name(), ".yaml") {
+		name, found := ashAlias(ash.Name())
+		if !found {
 			continue
 		}
-		name, _, _ := strings.Cut(ash.Name(), ".yaml")
-		//TODO: display path inside file
-		items = append(items, item(name))
+		names = append(names, name)
+	}
+	sortByHistory(phoemuxConfigPath, names)
+
+	bases := extendedAliases(phoemuxConfigPath, names)
+	sessions := Backend.ListSessions()
+	items := []list.Item{}
+	for _, name := range names {
+		items = append(items, newListItem(phoemuxConfigPath, name, bases, sessions))
 	}
 	return items
 }
 
-func ListAshes(phoemuxConfigPath string) {
+func ListAshes(phoemuxConfigPath string) error {
 	ashes, err := os.ReadDir(phoemuxConfigPath)
 	if err != nil {
-		fmt.Printf("Failed to read directory: %s\n", err)
+		return fmt.Errorf("Failed to read directory: %w", err)
 	}
 
-	var items []list.Item = getListOfItems(ashes)
+	var items []list.Item = getListOfItems(phoemuxConfigPath, ashes)
 
-	m := newList(items, phoemuxConfigPath)
+	config, err := LoadConfig(phoemuxConfigPath)
+	if err != nil {
+		return err
+	}
+	setTheme(config.Theme)
+
+	m := newList(items, phoemuxConfigPath, config)
 
 	if _, err := tea.NewProgram(m).Run(); err != nil {
-		fmt.Println("Error running program:", err)
-		os.Exit(1)
+		return fmt.Errorf("Error running program: %w", err)
+	}
+
+	if SessionChoice != "" {
+		return Attach(phoemuxConfigPath, SessionChoice)
+	}
+
+	if len(Choices) > 0 {
+		return recreateManyFromAshes(phoemuxConfigPath, Choices)
 	}
 
 	if Choice == "" {
-		os.Exit(0)
+		return nil
 	}
 
-	recreateFromAshes(phoemuxConfigPath, Choice)
+	return recreateFromAshes(phoemuxConfigPath, Choice)
 }
 
-func writeToCache(phoemuxConfigPath, alias string) {
-	cachePath := fmt.Sprintf(
-		"%s/cache",
+// OpenLast reopens the nth most recently opened ash with the vars it was opened with, see lastEntry
+func OpenLast(phoemuxConfigPath string, n int) error {
+	entry, err := lastEntry(phoemuxConfigPath, n)
+	if err != nil {
+		return err
+	}
+	restoreVars(entry)
+
+	if filepath.IsAbs(entry.Alias) {
+		return OpenLocal(phoemuxConfigPath, entry.Alias)
+	}
+	return recreateFromAshes(phoemuxConfigPath, entry.Alias)
+}
+
+func ashFilePath(phoemuxConfigPath, alias string) string {
+	return fmt.Sprintf(
+		"%s/%s.yaml",
 		phoemuxConfigPath,
+		alias,
 	)
+}
 
-	err := os.WriteFile(cachePath, []byte(alias), 0766)
+func readAsh(phoemuxConfigPath, alias string) (tmux.Ash, error) {
+	filePath := ashFilePath(phoemuxConfigPath, alias)
+
+	file, err := os.ReadFile(filePath)
 	if err != nil {
-		fmt.Printf("Failed to write to cache: %s\n", err)
-		return
+		return tmux.Ash{}, fmt.Errorf("Failed to read ash: %w", err)
 	}
+
+	return parseAsh(phoemuxConfigPath, filePath, "", file)
 }
 
-func OpenFromCache(phoemuxConfigPath string) {
-	cachePath := fmt.Sprintf(
-		"%s/cache",
-		phoemuxConfigPath,
-	)
+// findAshBySession returns the ash that creates the session, the history is searched first
+// since the session name may depend on the vars the ash was opened with
+func findAshBySession(phoemuxConfigPath, sessionName string) (tmux.Ash, bool) {
+	entries, _ := History(phoemuxConfigPath)
+	for _, entry := range entries {
+		if entry.SessionName != sessionName {
+			continue
+		}
+		ash, err := lookupAsh(phoemuxConfigPath, entry.Alias, entry.Vars)
+		if err == nil && ash.SessionName == sessionName {
+			return ash, true
+		}
+	}
+
+	ashes, err := GetSimpleList(phoemuxConfigPath)
+	if err != nil {
+		return tmux.Ash{}, false
+	}
+
+	for _, alias := range ashes {
+		ash, err := lookupAsh(phoemuxConfigPath, alias, Vars)
+		if err == nil && ash.SessionName == sessionName {
+			return ash, true
+		}
+	}
+	return tmux.Ash{}, false
+}
 
-	if !fileExist(cachePath) {
-		fmt.Printf("failed to get cache file\n")
-		os.Exit(5)
+// lookupAsh reads the ash of the alias, or of the path of a local ash, with the vars
+// and without checking its paths, the directory of the ash may be gone while its session still runs
+func lookupAsh(phoemuxConfigPath, alias string, vars map[string]string) (tmux.Ash, error) {
+	filePath := ashFilePath(phoemuxConfigPath, alias)
+	dir := ""
+	if filepath.IsAbs(alias) {
+		filePath = alias
+		dir = filepath.Dir(alias)
 	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return tmux.Ash{}, fmt.Errorf("Failed to read ash: %w", err)
+	}
+	return decodeAndValidate(phoemuxConfigPath, filePath, dir, content, vars, true)
+}
 
-	file, err := os.ReadFile(cachePath)
+func recreateFromAshes(phoemuxConfigPath, alias string) error {
+	ash, err := readAsh(phoemuxConfigPath, alias)
 	if err != nil {
-		fmt.Printf("failed to read from cache %v\n", err)
-		os.Exit(6)
+		return err
 	}
 
-	recreateFromAshes(phoemuxConfigPath, string(file))
+	recordOpen(phoemuxConfigPath, alias, ash.SessionName)
+
+	return openAsh(Backend, ash, runHooks)
 }
 
-func recreateFromAshes(phoemuxConfigPath, alias string) {
-	var ash tmux.Ash
+// recreateManyFromAshes creates the sessions of every alias without attaching to them
+// and then opens the last one, nothing is created when one of the ashes is not valid
+func recreateManyFromAshes(phoemuxConfigPath string, aliases []string) error {
+	ashes := []tmux.Ash{}
+	for _, alias := range aliases {
+		ash, err := readAsh(phoemuxConfigPath, alias)
+		if err != nil {
+			return fmt.Errorf("%s: %w", alias, err)
+		}
+		ashes = append(ashes, ash)
+	}
 
-	filePath := fmt.Sprintf(
-		"%s/%s.yaml",
-		phoemuxConfigPath,
-		alias,
-	)
+	last := len(ashes) - 1
+	for index, ash := range ashes {
+		recordOpen(phoemuxConfigPath, aliases[index], ash.SessionName)
+		if index == last {
+			return openAsh(Backend, ash, runHooks)
+		}
+		err := startAsh(Backend, ash, runHooks)
+		if err != nil {
+			return fmt.Errorf("%s: %w", aliases[index], err)
+		}
+	}
+	return nil
+}
 
-	file, err := os.ReadFile(filePath)
+// openAsh builds the session if it is not running and changes to it, hooks
+// runs the onCreate or onAttach hooks depending on which one happened
+func openAsh(backend tmux.Backend, ash tmux.Ash, hooks func(tmux.Ash, []string)) error {
+	if backend.HasSession(ash.SessionName) {
+		hooks(ash, ash.Hooks.OnAttach)
+		return backend.ChangeSession(ash)
+	}
+
+	err := startAsh(backend, ash, hooks)
 	if err != nil {
-		fmt.Printf("Failed to read ash: %s\n", err)
-		return
+		return err
 	}
+	return backend.ChangeSession(ash)
+}
 
-	err = yaml.Unmarshal(file, &ash)
+// startAsh creates the session of the ash when it is not running, without attaching to it
+func startAsh(backend tmux.Backend, ash tmux.Ash, hooks func(tmux.Ash, []string)) error {
+	if backend.HasSession(ash.SessionName) {
+		return nil
+	}
+
+	err := buildSession(backend, ash)
 	if err != nil {
-		fmt.Printf("Failed to unmarshall ash: %s\n", err)
-		return
+		return err
 	}
+	hooks(ash, ash.Hooks.OnCreate)
+	return nil
+}
 
-	writeToCache(phoemuxConfigPath, alias)
+// buildSession creates the session with its windows and panes and selects the default window,
+// a session that fails halfway is killed so the next open does not attach to it
+func buildSession(backend tmux.Backend, ash tmux.Ash) error {
+	err := backend.NewSession(ash)
+	if err != nil {
+		return fmt.Errorf("failed to open session %s: %w", ash.SessionName, err)
+	}
 
-	if tmux.HasSession(ash.SessionName) {
-		tmux.ChangeSession(ash)
-		return
+	err = buildWindows(backend, ash)
+	if err != nil {
+		killErr := backend.Kill(ash.SessionName)
+		if killErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to kill session %s: %w", ash.SessionName, killErr))
+		}
+		return err
 	}
+	return nil
+}
 
-	tmux.NewSession(ash)
+// buildWindows fills the new session with the windows and panes of the ash
+func buildWindows(backend tmux.Backend, ash tmux.Ash) error {
+	var err error
 	for i, window := range ash.Windows {
-		if i == 0 {
-			tmux.RenameWindow(ash, "0", window.Name)
-		} else {
-			tmux.NewWindow(ash, window)
+		// the first window is created along with the session
+		if i > 0 {
+			err = backend.NewWindow(ash, window)
+			if err != nil {
+				return fmt.Errorf("failed to create window %s: %w", window.Name, err)
+			}
+		}
+
+		for j, terminal := range window.Terminals {
+			if j > 0 {
+				err = backend.SplitWindow(ash, window, terminal)
+			} else if i == 0 && len(tmux.PaneEnv(window, terminal)) > 0 {
+				// the first pane was started by new-session before the
+				// window and terminal variables could be set
+				err = backend.RespawnPane(ash, window, terminal)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create pane in window %s: %w", window.Name, err)
+			}
+
+			if terminal.Command == "" {
+				continue
+			}
+			err = backend.RunCommand(
+				ash.SessionName,
+				window.Name,
+				terminal.Command,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to run command %s: %w", terminal.Command, err)
+			}
 		}
 
-		tmux.RunCommand(
-			ash.SessionName,
-			window.Name,
-			window.Terminals[0].Command,
-		)
+		if window.Layout != "" {
+			err = backend.SelectLayout(ash, window)
+			if err != nil {
+				return fmt.Errorf("failed to select layout of window %s: %w", window.Name, err)
+			}
+		}
 	}
 
-	tmux.SetWindows(ash)
-	tmux.ChangeSession(ash)
+	err = backend.SetWindows(ash)
+	if err != nil {
+		return fmt.Errorf("failed to select window %s: %w", ash.DefaultWindow, err)
+	}
+	return nil
 }
 
-func Delete(phoemuxConfigPath, alias string) {
+// Attach changes to the session running the onAttach hooks of its ash, if any
+func Attach(phoemuxConfigPath, sessionName string) error {
+	ash, found := findAshBySession(phoemuxConfigPath, sessionName)
+	if found && sessionName != "" {
+		runHooks(ash, ash.Hooks.OnAttach)
+	}
+	return Backend.ChangeSession(tmux.Ash{SessionName: sessionName})
+}
+
+// Kill runs the onKill hooks of the ash that created the session, if any,
+// and then kills the session
+func Kill(phoemuxConfigPath, sessionName string) error {
+	return killWithHooks(phoemuxConfigPath, sessionName, runHooks)
+}
+
+// killWithHooks does the work of Kill, hooks runs the onKill hooks
+func killWithHooks(phoemuxConfigPath, sessionName string, hooks func(tmux.Ash, []string)) error {
+	ash, found := findAshBySession(phoemuxConfigPath, sessionName)
+	if found {
+		hooks(ash, ash.Hooks.OnKill)
+	}
+	return Backend.Kill(sessionName)
+}
+
+func Delete(phoemuxConfigPath, alias string) error {
 	if alias == "" {
-		fmt.Printf("delete command expects an alias\n")
-		return
+		return fmt.Errorf("delete command expects an alias")
 	}
 	exist := ashExist(phoemuxConfigPath, alias)
 	if !exist {
-		fmt.Printf("Ash does not exist\n")
-		return
+		return fmt.Errorf("Ash %s does not exist", alias)
 	}
 
-	os.Remove(
-		phoemuxConfigPath + "/" + alias + ".yaml",
-	)
-}
-
-func ashExist(phoemuxConfigPath, alias string) bool {
-	ashes, err := os.ReadDir(phoemuxConfigPath)
+	err := os.Remove(ashFilePath(phoemuxConfigPath, alias))
 	if err != nil {
-		fmt.Printf("Failed to read directory: %s\n", err)
+		return fmt.Errorf("Failed to delete ash: %w", err)
 	}
 
-	for _, ash := range ashes {
-		if !strings.Contains(ash.Name(), ".yaml") {
-			continue
-		}
-		name, _, _ := strings.Cut(ash.Name(), ".yaml")
-		if alias == name {
-			return true
-		}
+	return removeFromHistory(phoemuxConfigPath, alias)
+}
+
+// ashExist tells if the alias has an ash file, errors reading it are reported by whoever reads it
+func ashExist(phoemuxConfigPath, alias string) bool {
+	if _, found := ashAlias(alias + ".yaml"); !found {
+		return false
 	}
-	return false
+	_, err := os.Stat(ashFilePath(phoemuxConfigPath, alias))
+	return err == nil
 }
 
-func Open(phoemuxConfigPath, alias string) {
+func Open(phoemuxConfigPath, alias string) error {
 	exist := ashExist(phoemuxConfigPath, alias)
-	if exist {
-		fmt.Printf("creating session\n")
-		recreateFromAshes(phoemuxConfigPath, alias)
-	} else {
-		fmt.Printf("ash not found, can not create session\n")
+	if !exist {
+		return fmt.Errorf("ash not found, can not create session")
 	}
+	fmt.Printf("creating session\n")
+	return recreateFromAshes(phoemuxConfigPath, alias)
 }
 
 func getEditor() string {
@@ -1,39 +1,45 @@
 package core
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
-	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+	"github.com/jhonnyV-V/phoemux/tmux/tmuxtest"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func TestMain(m *testing.M) {
 	//before tests
-	configPath := GetConfigPath()
-	oldConfigPath := path.Join(configPath, "..", "old-phoemux")
-	fmt.Printf("move config folder to %s\n", oldConfigPath)
-	err := os.Rename(configPath, oldConfigPath)
+	tmpDir, err := os.MkdirTemp("", "phoemux-test")
 	if err != nil {
-		fmt.Printf("failed to move config directory to %s with error %s\n", oldConfigPath, err)
+		fmt.Printf("failed to create temporary directory with error %s\n", err)
 		os.Exit(1)
 	}
+	// os.UserConfigDir uses XDG_CONFIG_HOME on linux and HOME on macos
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+	os.Setenv("HOME", tmpDir)
 	CreateConfigDir()
 	OpenEditor = false
 
 	exitVal := m.Run()
 	//cleanup
-	err = os.RemoveAll(configPath)
+	err = os.RemoveAll(tmpDir)
 	if err != nil {
 		fmt.Printf("failed remove test config directory with error %s\n", err)
 		os.Exit(1)
 	}
-	err = os.Rename(oldConfigPath, configPath)
-	if err != nil {
-		fmt.Printf("failed to move old config directory to %s with error %s\n", configPath, err)
-		os.Exit(1)
-	}
 	os.Exit(exitVal)
 }
 
@@ -44,7 +50,10 @@ func TestCreate(t *testing.T) {
 		os.Exit(1)
 	}
 	phoemuxConfigPath := CreateConfigDir()
-	Create(phoemuxConfigPath, pwd, "phoemux")
+	err = Create(phoemuxConfigPath, pwd, "phoemux", CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create ash: %s", err)
+	}
 	created := ashExist(phoemuxConfigPath, "phoemux")
 	if !created {
 		t.Fatal("failed to create file")
@@ -82,10 +91,1649 @@ func TestList(t *testing.T) {
 func TestDelete(t *testing.T) {
 	phoemuxConfigPath := GetConfigPath()
 
-	Delete(phoemuxConfigPath, "phoemux")
+	err := Delete(phoemuxConfigPath, "phoemux")
+	if err != nil {
+		t.Fatalf("failed to delete ash: %s", err)
+	}
 
 	deleted := ashExist(phoemuxConfigPath, "phoemux")
 	if deleted {
 		t.Fatal("failed to delete file")
 	}
+
+	err = Delete(phoemuxConfigPath, "phoemux")
+	if err == nil {
+		t.Fatal("expected deleting a missing ash to fail")
+	}
+}
+
+func writeAsh(t *testing.T, alias, content string) {
+	t.Helper()
+	filePath := filepath.Join(GetConfigPath(), alias+".yaml")
+	err := os.WriteFile(filePath, []byte(content), 0666)
+	if err != nil {
+		t.Fatalf("failed to write ash %s: %s", alias, err)
+	}
+	t.Cleanup(func() {
+		os.Remove(filePath)
+	})
+}
+
+func newFake(t *testing.T) *tmuxtest.Fake {
+	t.Helper()
+	fake := tmuxtest.New()
+	Backend = fake
+	t.Cleanup(func() {
+		Backend = tmux.Tmux{}
+	})
+	return fake
+}
+
+func TestRecreateFromAshes(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	os.Mkdir(filepath.Join(projectPath, "api"), 0766)
+	writeAsh(t, "project", fmt.Sprintf(`path: "%s"
+sessionName: "project"
+defaultWindow: servers
+env:
+  APP_ENV: test
+windows:
+- name: code
+  env:
+    EDITOR: nvim
+  terminals:
+  - command: nvim .
+- name: servers
+  path: api
+  split: horizontal
+  layout: tiled
+  terminals:
+  - command: make api
+  - command: make worker
+    path: /tmp
+    env:
+      QUEUE: default
+`, projectPath))
+
+	err := recreateFromAshes(GetConfigPath(), "project")
+	if err != nil {
+		t.Fatalf("failed to recreate ash: %s", err)
+	}
+
+	session, ok := fake.Sessions["project"]
+	if !ok {
+		t.Fatal("session was not created")
+	}
+	if fake.Current != "project" {
+		t.Fatalf("expected to attach to project, attached to %q", fake.Current)
+	}
+	if session.ActiveWindow != "servers" {
+		t.Fatalf("expected servers to be the active window, got %s", session.ActiveWindow)
+	}
+	if session.Env["APP_ENV"] != "test" {
+		t.Fatalf("session environment not set: %#v", session.Env)
+	}
+	if len(session.Windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(session.Windows))
+	}
+
+	code := session.Windows[0]
+	if code.Name != "code" || len(code.Panes) != 1 {
+		t.Fatalf("unexpected code window %#v", code)
+	}
+	if code.Panes[0].Env["EDITOR"] != "nvim" {
+		t.Fatalf("first pane environment not set: %#v", code.Panes[0].Env)
+	}
+	if !slices.Equal(code.Panes[0].Keys, []string{"nvim ."}) {
+		t.Fatalf("unexpected keys %#v", code.Panes[0].Keys)
+	}
+
+	servers := session.Windows[1]
+	if servers.Layout != "tiled" {
+		t.Fatalf("layout not applied, got %q", servers.Layout)
+	}
+	if len(servers.Panes) != 2 {
+		t.Fatalf("expected 2 panes, got %d", len(servers.Panes))
+	}
+	if servers.Panes[0].Path != filepath.Join(projectPath, "api") {
+		t.Fatalf("unexpected window path %s", servers.Panes[0].Path)
+	}
+	if servers.Panes[1].Path != "/tmp" || servers.Panes[1].Env["QUEUE"] != "default" {
+		t.Fatalf("unexpected pane %#v", servers.Panes[1])
+	}
+	if !slices.Equal(servers.Panes[1].Keys, []string{"make worker"}) {
+		t.Fatalf("unexpected keys %#v", servers.Panes[1].Keys)
+	}
+}
+
+func TestOpenExistingSession(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	writeAsh(t, "project", fmt.Sprintf(`path: "%s"
+sessionName: "project"
+defaultWindow: code
+hooks:
+  onCreate:
+  - echo create >> hooks.log
+  onAttach:
+  - echo attach >> hooks.log
+windows:
+- name: code
+  terminals:
+  - command: ls
+`, projectPath))
+
+	for range 2 {
+		err := Open(GetConfigPath(), "project")
+		if err != nil {
+			t.Fatalf("failed to open ash: %s", err)
+		}
+	}
+
+	if len(fake.Sessions) != 1 || fake.Current != "project" {
+		t.Fatalf("expected to attach to the existing session, got %#v", fake.Sessions)
+	}
+	if len(fake.Sessions["project"].Windows[0].Panes[0].Keys) != 1 {
+		t.Fatal("commands were sent again to an existing session")
+	}
+
+	log, err := os.ReadFile(filepath.Join(projectPath, "hooks.log"))
+	if err != nil {
+		t.Fatalf("hooks did not run: %s", err)
+	}
+	if string(log) != "create\nattach\n" {
+		t.Fatalf("unexpected hooks output %q", log)
+	}
+}
+
+func TestOpenLast(t *testing.T) {
+	fake := newFake(t)
+	writeAsh(t, "cached", fmt.Sprintf(`path: "%s"
+sessionName: "cached"
+defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: ls
+`, t.TempDir()))
+
+	err := Open(GetConfigPath(), "cached")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+	err = fake.Kill("cached")
+	if err != nil {
+		t.Fatalf("failed to kill session: %s", err)
+	}
+
+	err = OpenLast(GetConfigPath(), 1)
+	if err != nil {
+		t.Fatalf("failed to open last ash: %s", err)
+	}
+	if !fake.HasSession("cached") {
+		t.Fatal("last session was not recreated")
+	}
+}
+
+func TestOpenFailsWhenTmuxFails(t *testing.T) {
+	fake := newFake(t)
+	fake.Errors["NewWindow"] = tmux.ErrServerNotRunning
+	writeAsh(t, "broken", fmt.Sprintf(`path: "%s"
+sessionName: "broken"
+defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: ls
+- name: servers
+  terminals:
+  - command: ls
+`, t.TempDir()))
+
+	err := Open(GetConfigPath(), "broken")
+	if !errors.Is(err, tmux.ErrServerNotRunning) {
+		t.Fatalf("expected server not running error, got %v", err)
+	}
+	if fake.Current != "" {
+		t.Fatal("attached to a session that failed to be created")
+	}
+	if fake.HasSession("broken") || !slices.Equal(fake.Killed, []string{"broken"}) {
+		t.Fatalf("expected the half built session to be killed, got %v", fake.Killed)
+	}
+
+	// the next open builds the session again instead of attaching to a broken one
+	delete(fake.Errors, "NewWindow")
+	err = Open(GetConfigPath(), "broken")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+	if len(fake.Sessions["broken"].Windows) != 2 {
+		t.Fatalf("expected the session to be rebuilt %#v", fake.Sessions["broken"])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	newFake(t)
+	writeAsh(t, "invalid", fmt.Sprintf(`path: "%s"
+sessionName: "invalid"
+defaultWindow: tests
+windows:
+- name: code
+  split: diagonal
+  terminals: []
+`, t.TempDir()))
+	writeAsh(t, "unknown", fmt.Sprintf(`path: "%s"
+sessionName: "unknown"
+windows:
+- name: code
+  termnals:
+  - command: ls
+`, t.TempDir()))
+
+	err := Validate(GetConfigPath(), "invalid")
+	if err == nil {
+		t.Fatal("expected invalid ash to fail")
+	}
+	for _, expected := range []string{
+		`invalid.yaml:6:10: split must be one of horizontal, vertical, got "diagonal"`,
+		"invalid.yaml:7:14: window code needs at least one terminal",
+		"invalid.yaml:3:16: defaultWindow tests is not one of the windows",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in\n%s", expected, err)
+		}
+	}
+
+	err = Validate(GetConfigPath(), "unknown")
+	if err == nil || !strings.Contains(err.Error(), `[5:3] unknown field "termnals"`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+
+	err = Open(GetConfigPath(), "invalid")
+	if err == nil {
+		t.Fatal("opened an invalid ash")
+	}
+}
+
+func TestKill(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	writeAsh(t, "killed", fmt.Sprintf(`path: "%s"
+sessionName: "killed"
+defaultWindow: code
+hooks:
+  onKill:
+  - touch killed
+windows:
+- name: code
+  terminals:
+  - command: ls
+`, projectPath))
+
+	err := Open(GetConfigPath(), "killed")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+
+	err = Kill(GetConfigPath(), "killed")
+	if err != nil {
+		t.Fatalf("failed to kill session: %s", err)
+	}
+	if fake.HasSession("killed") {
+		t.Fatal("session is still alive")
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "killed")); err != nil {
+		t.Fatalf("onKill hook did not run: %s", err)
+	}
+
+	err = Kill(GetConfigPath(), "killed")
+	if !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Fatalf("expected session not found error, got %v", err)
+	}
+
+	// hooks still run when a directory of the ash is gone
+	writeAsh(t, "moved", fmt.Sprintf(`path: "%s"
+sessionName: "moved"
+hooks:
+  onKill:
+  - touch moved
+windows:
+- name: web
+  path: web
+  terminals:
+  - command: ls
+`, projectPath))
+	os.Mkdir(filepath.Join(projectPath, "web"), 0755)
+	err = Open(GetConfigPath(), "moved")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+	os.Remove(filepath.Join(projectPath, "web"))
+	err = Kill(GetConfigPath(), "moved")
+	if err != nil {
+		t.Fatalf("failed to kill session: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "moved")); err != nil {
+		t.Fatalf("onKill hook did not run without the window directory: %s", err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("failed to generate schema: %s", err)
+	}
+	for _, expected := range []string{
+		`"sessionName"`,
+		`"terminals"`,
+		`"onKill"`,
+		`"additionalProperties": false`,
+		`"horizontal"`,
+	} {
+		if !strings.Contains(string(schema), expected) {
+			t.Fatalf("expected %s in schema\n%s", expected, schema)
+		}
+	}
+	var parsed map[string]any
+	err = json.Unmarshal(schema, &parsed)
+	if err != nil {
+		t.Fatalf("schema is not valid json: %s", err)
+	}
+	if _, found := parsed["required"]; found {
+		t.Fatalf("extended ashes may leave out every field, got required %v", parsed["required"])
+	}
+	template := parsed["properties"].(map[string]any)["template"].(map[string]any)
+	if template["type"] != "boolean" {
+		t.Fatalf("expected template to be a boolean, got %v", template)
+	}
+
+	pwd := t.TempDir()
+	phoemuxConfigPath := GetConfigPath()
+	Create(phoemuxConfigPath, pwd, "schema", CreateOptions{})
+	defer Delete(phoemuxConfigPath, "schema")
+
+	if !fileExist(SchemaPath(phoemuxConfigPath)) {
+		t.Fatal("schema was not written to the config directory")
+	}
+	content, err := os.ReadFile(filepath.Join(phoemuxConfigPath, "schema.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read ash: %s", err)
+	}
+	if !strings.HasPrefix(string(content), "# yaml-language-server: $schema="+SchemaPath(phoemuxConfigPath)) {
+		t.Fatalf("missing schema header in\n%s", content)
+	}
+	err = Validate(phoemuxConfigPath, "schema")
+	if err != nil {
+		t.Fatalf("default ash is not valid: %s", err)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	os.Mkdir(filepath.Join(projectPath, "web"), 0766)
+	writeAsh(t, "original", fmt.Sprintf(`path: "%s"
+sessionName: "running"
+defaultWindow: servers
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+- name: servers
+  layout: tiled
+  terminals:
+  - command: make api
+  - command: npm run dev
+    path: web
+`, projectPath))
+
+	err := Open(GetConfigPath(), "original")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+
+	err = Freeze(GetConfigPath(), "frozen", "running")
+	if err != nil {
+		t.Fatalf("failed to freeze session: %s", err)
+	}
+	defer Delete(GetConfigPath(), "frozen")
+
+	err = Freeze(GetConfigPath(), "frozen", "running")
+	if err == nil {
+		t.Fatal("froze over an existing ash")
+	}
+
+	delete(fake.Sessions, "running")
+	err = Open(GetConfigPath(), "frozen")
+	if err != nil {
+		t.Fatalf("failed to open frozen ash: %s", err)
+	}
+
+	session := fake.Sessions["running"]
+	if session.ActiveWindow != "servers" || len(session.Windows) != 2 {
+		t.Fatalf("unexpected session %#v", session)
+	}
+	servers := session.Windows[1]
+	if servers.Layout != "tiled" || len(servers.Panes) != 2 {
+		t.Fatalf("unexpected window %#v", servers)
+	}
+	if servers.Panes[1].Path != filepath.Join(projectPath, "web") {
+		t.Fatalf("unexpected pane path %s", servers.Panes[1].Path)
+	}
+	if !slices.Equal(servers.Panes[1].Keys, []string{"npm run dev"}) {
+		t.Fatalf("unexpected pane keys %#v", servers.Panes[1].Keys)
+	}
+
+	// tmux names the windows of sessions made by hand after their commands
+	fake.Sessions["handmade"] = &tmuxtest.Session{
+		Name:         "handmade",
+		ActiveWindow: "python3.11",
+		Windows: []*tmuxtest.Window{
+			{Name: "zsh", Panes: []*tmuxtest.Pane{{Path: projectPath}}},
+			{Name: "zsh", Panes: []*tmuxtest.Pane{{Path: projectPath}}},
+			{Name: "python3.11", Panes: []*tmuxtest.Pane{{Path: projectPath}}},
+		},
+	}
+	err = Freeze(GetConfigPath(), "handmade", "handmade")
+	if err != nil {
+		t.Fatalf("failed to freeze session made by hand: %s", err)
+	}
+	defer Delete(GetConfigPath(), "handmade")
+	ash, err := readAsh(GetConfigPath(), "handmade")
+	if err != nil {
+		t.Fatalf("frozen ash is not valid: %s", err)
+	}
+	names := []string{}
+	for _, window := range ash.Windows {
+		names = append(names, window.Name)
+	}
+	if !slices.Equal(names, []string{"zsh", "zsh-2", "python3-11"}) || ash.DefaultWindow != "python3-11" {
+		t.Fatalf("unexpected windows %v with default %s", names, ash.DefaultWindow)
+	}
+}
+
+func TestImport(t *testing.T) {
+	projectPath := t.TempDir()
+	projects := t.TempDir()
+	tmuxinator := filepath.Join(projects, "blog.yml")
+	os.WriteFile(tmuxinator, []byte(fmt.Sprintf(`name: blog
+root: %s
+on_project_start: docker compose up -d
+pre_window: nvm use
+startup_window: logs
+windows:
+  - editor:
+      layout: main-vertical
+      panes:
+        - vim
+        - - bundle
+          - rake
+  - logs: tail -f log/development.log
+  - shell: {root: %s/log, layout: tiled}
+`, projectPath, projectPath)), 0666)
+	os.Mkdir(filepath.Join(projectPath, "log"), 0755)
+	tmuxp := filepath.Join(projects, "api.json")
+	os.WriteFile(tmuxp, []byte(fmt.Sprintf(`{
+  "session_name": "api",
+  "start_directory": "%s",
+  "environment": {"APP_ENV": "development"},
+  "windows": [
+    {"window_name": "editor", "focus": true, "panes": [{"shell_command": ["vim"]}, "blank"]},
+    {"window_name": "server", "panes": ["make run"]}
+  ]
+}`, projectPath)), 0666)
+
+	alias, err := Import(GetConfigPath(), "tmuxinator", tmuxinator, "")
+	if err != nil {
+		t.Fatalf("failed to import tmuxinator project: %s", err)
+	}
+	defer Delete(GetConfigPath(), alias)
+	ash, err := readAsh(GetConfigPath(), alias)
+	if err != nil {
+		t.Fatalf("imported tmuxinator project is not valid: %s", err)
+	}
+	if alias != "blog" || ash.DefaultWindow != "logs" || ash.Path != projectPath {
+		t.Fatalf("unexpected ash %s %#v", alias, ash)
+	}
+	if !slices.Equal(ash.Hooks.OnCreate, []string{"docker compose up -d"}) {
+		t.Fatalf("unexpected hooks %#v", ash.Hooks)
+	}
+	editor := ash.Windows[0]
+	if editor.Layout != "main-vertical" || len(editor.Terminals) != 2 {
+		t.Fatalf("unexpected window %#v", editor)
+	}
+	if editor.Terminals[1].Command != "nvm use; bundle; rake" {
+		t.Fatalf("unexpected command %q", editor.Terminals[1].Command)
+	}
+	shell := ash.Windows[2]
+	if shell.Path != filepath.Join(projectPath, "log") || shell.Layout != "tiled" ||
+		len(shell.Terminals) != 1 || shell.Terminals[0].Command != "" {
+		t.Fatalf("expected a window without panes to open a shell, got %#v", shell)
+	}
+
+	alias, err = Import(GetConfigPath(), "tmuxp", tmuxp, "backend")
+	if err != nil {
+		t.Fatalf("failed to import tmuxp session: %s", err)
+	}
+	defer Delete(GetConfigPath(), alias)
+	ash, err = readAsh(GetConfigPath(), alias)
+	if err != nil {
+		t.Fatalf("imported tmuxp session is not valid: %s", err)
+	}
+	if alias != "backend" || ash.SessionName != "api" || ash.DefaultWindow != "editor" {
+		t.Fatalf("unexpected ash %s %#v", alias, ash)
+	}
+	if ash.Env["APP_ENV"] != "development" {
+		t.Fatalf("unexpected env %#v", ash.Env)
+	}
+	if len(ash.Windows[0].Terminals) != 2 || ash.Windows[0].Terminals[1].Command != "" {
+		t.Fatalf("unexpected terminals %#v", ash.Windows[0].Terminals)
+	}
+
+	_, err = Import(GetConfigPath(), "tmuxp", tmuxp, "backend")
+	if err == nil {
+		t.Fatal("imported over an existing ash")
+	}
+}
+
+func TestExport(t *testing.T) {
+	projectPath := t.TempDir()
+	writeAsh(t, "export", fmt.Sprintf(`path: "%s"
+sessionName: "export"
+env:
+  GREETING: "it's me"
+hooks:
+  onCreate:
+    - touch created
+windows:
+- name: code
+  terminals:
+  - command: echo "$GREETING"
+- name: servers
+  path: web
+  split: vertical
+  terminals:
+  - command: make run
+  - size: 30%%
+    path: /tmp
+`, projectPath))
+	err := os.Mkdir(filepath.Join(projectPath, "web"), 0o755)
+	if err != nil {
+		t.Fatalf("failed to create web dir: %s", err)
+	}
+
+	script, err := Export(GetConfigPath(), "export", "sh")
+	if err != nil {
+		t.Fatalf("failed to export ash: %s", err)
+	}
+
+	expected := []string{
+		"#!/bin/sh",
+		`ROOT="${PHOEMUX_ROOT:-$(cd "$(dirname "$0")" && pwd)}"`,
+		"if ! tmux has-session -t=export 2>/dev/null; then",
+		"\ttmux new-session -s export -d -c \"$ROOT\"" + ` -n code -e 'GREETING=it'\''s me'`,
+		"\ttmux send-keys -t=export:code 'echo \"$GREETING\"' C-m",
+		"\ttmux new-window -c \"$ROOT\"/web -n servers -t=export",
+		"\ttmux split-window -v -c /tmp -t=export:servers -l 30%",
+		"\ttmux select-window -t=export:code",
+		"\t(cd \"$ROOT\"" + ` && export 'GREETING=it'\''s me' && touch created)`,
+		"\ttmux switch-client -t=export",
+		"\ttmux attach-session -t=export",
+	}
+	for _, line := range expected {
+		if !strings.Contains(script, line) {
+			t.Fatalf("expected script to contain %q, got\n%s", line, script)
+		}
+	}
+
+	_, err = Export(GetConfigPath(), "export", "fish")
+	if err == nil {
+		t.Fatal("exported an unknown format")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	writeAsh(t, "plan", fmt.Sprintf(`path: "%s"
+sessionName: "plan"
+hooks:
+  onCreate:
+  - echo create >> hooks.log
+  onAttach:
+  - echo attach >> hooks.log
+windows:
+- name: code
+  terminals:
+  - command: vim .
+- name: servers
+  terminals:
+  - command: make run
+`, projectPath))
+
+	plan, err := Plan(GetConfigPath(), "plan")
+	if err != nil {
+		t.Fatalf("failed to plan ash: %s", err)
+	}
+	expected := []string{
+		"tmux new-session -s plan -d -c " + projectPath + " -n code",
+		"tmux send-keys -t=plan:code 'vim .' C-m",
+		"tmux new-window -c " + projectPath + " -n servers -t=plan",
+		"tmux send-keys -t=plan:servers 'make run' C-m",
+		"tmux select-window -t=plan:code",
+		"sh -c 'echo create >> hooks.log'",
+	}
+	if !slices.Equal(plan[:len(plan)-1], expected) {
+		t.Fatalf("unexpected plan\n%s", strings.Join(plan, "\n"))
+	}
+	if len(fake.Sessions) != 0 {
+		t.Fatal("planning created a session")
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "hooks.log")); err == nil {
+		t.Fatal("planning ran the hooks")
+	}
+
+	fake.Sessions["plan"] = &tmuxtest.Session{Name: "plan"}
+	plan, err = Plan(GetConfigPath(), "plan")
+	if err != nil {
+		t.Fatalf("failed to plan ash: %s", err)
+	}
+	if len(plan) != 2 || plan[0] != "sh -c 'echo attach >> hooks.log'" {
+		t.Fatalf("expected to plan attaching to the session, got\n%s", strings.Join(plan, "\n"))
+	}
+}
+
+func TestLocalAsh(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	nested := filepath.Join(projectPath, "cmd", "server")
+	os.MkdirAll(nested, 0755)
+	os.WriteFile(filepath.Join(projectPath, LocalAshName), []byte(`sessionName: "local"
+windows:
+- name: server
+  path: cmd/server
+  terminals:
+  - command: go run .
+`), 0666)
+
+	_, err := FindLocalAsh(t.TempDir())
+	if !errors.Is(err, ErrNoLocalAsh) {
+		t.Fatalf("expected ErrNoLocalAsh, got %v", err)
+	}
+
+	localAsh, err := FindLocalAsh(nested)
+	if err != nil {
+		t.Fatalf("failed to find local ash: %s", err)
+	}
+	if localAsh != filepath.Join(projectPath, LocalAshName) {
+		t.Fatalf("found the wrong ash %s", localAsh)
+	}
+
+	err = OpenLocal(GetConfigPath(), localAsh)
+	if err != nil {
+		t.Fatalf("failed to open local ash: %s", err)
+	}
+	session, ok := fake.Sessions["local"]
+	if !ok {
+		t.Fatalf("session was not created, got %#v", fake.Sessions)
+	}
+	if session.Windows[0].Panes[0].Path != nested {
+		t.Fatalf("expected the pane to start in %s, got %s", nested, session.Windows[0].Panes[0].Path)
+	}
+
+	fake.Kill("local")
+	err = OpenLast(GetConfigPath(), 1)
+	if err != nil {
+		t.Fatalf("failed to reopen local ash from the history: %s", err)
+	}
+	if _, ok := fake.Sessions["local"]; !ok {
+		t.Fatal("last did not reopen the local ash")
+	}
+}
+
+func TestExtends(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	writeAsh(t, "base-go", `env:
+  GOFLAGS: "-race"
+  CGO_ENABLED: "0"
+hooks:
+  onCreate:
+  - echo base >> hooks.log
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+- name: tests
+  terminals:
+  - command: go test ./...
+- name: logs
+  terminals:
+  - command: tail -f app.log
+`)
+	writeAsh(t, "service", fmt.Sprintf(`extends: base-go
+path: "%s"
+sessionName: "service"
+env:
+  CGO_ENABLED: "1"
+hooks:
+  onCreate:
+  - echo service >> hooks.log
+windows:
+- name: tests
+  terminals:
+  - command: go test ./... -count=1
+- name: server
+  terminals:
+  - command: go run ./cmd/server
+`, projectPath))
+
+	ash, err := readAsh(GetConfigPath(), "service")
+	if err != nil {
+		t.Fatalf("failed to read extended ash: %s", err)
+	}
+	names := []string{}
+	for _, window := range ash.Windows {
+		names = append(names, window.Name)
+	}
+	if !slices.Equal(names, []string{"code", "tests", "logs", "server"}) {
+		t.Fatalf("unexpected windows %v", names)
+	}
+	if ash.Windows[1].Terminals[0].Command != "go test ./... -count=1" {
+		t.Fatalf("tests window was not overridden: %#v", ash.Windows[1])
+	}
+	if ash.Env["GOFLAGS"] != "-race" || ash.Env["CGO_ENABLED"] != "1" {
+		t.Fatalf("unexpected env %#v", ash.Env)
+	}
+	if !slices.Equal(ash.Hooks.OnCreate, []string{"echo base >> hooks.log", "echo service >> hooks.log"}) {
+		t.Fatalf("unexpected hooks %#v", ash.Hooks)
+	}
+
+	err = Open(GetConfigPath(), "service")
+	if err != nil {
+		t.Fatalf("failed to open extended ash: %s", err)
+	}
+	if len(fake.Sessions["service"].Windows) != 4 {
+		t.Fatalf("unexpected session %#v", fake.Sessions["service"])
+	}
+
+	err = ValidateAll(GetConfigPath())
+	if err != nil {
+		t.Fatalf("partial base should be valid when extended: %s", err)
+	}
+	err = Validate(GetConfigPath(), "base-go")
+	if err == nil {
+		t.Fatal("a partial ash can not be opened")
+	}
+
+	writeAsh(t, "loop", `extends: loop
+windows: []
+`)
+	_, err = readAsh(GetConfigPath(), "loop")
+	if err == nil || !strings.Contains(err.Error(), "extends itself") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+
+	writeAsh(t, "base-sized", `windows:
+- name: code
+  terminals:
+  - command: nvim .
+  - size: huge
+`)
+	writeAsh(t, "sized", fmt.Sprintf(`extends: base-sized
+path: "%s"
+sessionName: "sized"
+windows:
+- name: server
+  split: diagonal
+  terminals:
+  - command: go run .
+- name: code
+`, projectPath))
+	_, err = readAsh(GetConfigPath(), "sized")
+	if err == nil {
+		t.Fatal("expected the sizes of the merged ash to be checked")
+	}
+	for _, expected := range []string{
+		ashFilePath(GetConfigPath(), "base-sized") + ":5:11: size",
+		ashFilePath(GetConfigPath(), "sized") + ":6:10: split",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in %s", expected, err)
+		}
+	}
+
+	entries, err := os.ReadDir(GetConfigPath())
+	if err != nil {
+		t.Fatalf("failed to read config dir: %s", err)
+	}
+	items := getListOfItems(GetConfigPath(), entries)
+	m := newList(items, GetConfigPath(), Config{})
+	for index, listItem := range items {
+		if listItem.(item).alias == "base-go" {
+			m.list.Select(index)
+		}
+	}
+	selected := m.list.SelectedItem().(item)
+	if !selected.base || selected.err != nil || len(selected.ash.Windows) != 3 {
+		t.Fatalf("expected base-go to be listed as a base %#v", selected)
+	}
+	m, cmd := press(t, m, "enter")
+	if cmd != nil || Choice != "" || !strings.Contains(m.status, "base of other ashes") {
+		t.Fatalf("opened a base ash, status %q", m.status)
+	}
+
+	// a complete ash that is extended is still listed and checked as an ash
+	for index, listItem := range items {
+		if i := listItem.(item); i.alias == "service" && i.base {
+			t.Fatalf("expected the complete ash service to not be a base %#v", items[index])
+		}
+	}
+	writeAsh(t, "service-debug", `extends: service
+sessionName: "service-debug"
+`)
+	entries, _ = os.ReadDir(GetConfigPath())
+	for _, listItem := range getListOfItems(GetConfigPath(), entries) {
+		if i := listItem.(item); i.alias == "service" && (i.base || i.err != nil) {
+			t.Fatalf("expected the extended complete ash service to be listed as an ash %#v", i)
+		}
+	}
+	if partialAsh(GetConfigPath(), "service") || !partialAsh(GetConfigPath(), "base-go") {
+		t.Fatal("expected only base-go to be partial")
+	}
+	os.RemoveAll(projectPath)
+	entries, _ = os.ReadDir(GetConfigPath())
+	for _, listItem := range getListOfItems(GetConfigPath(), entries) {
+		if i := listItem.(item); i.alias == "service" && (i.base || i.err == nil) {
+			t.Fatalf("expected the missing path of the extended complete ash to be reported %#v", i)
+		}
+	}
+}
+
+func TestVars(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	os.MkdirAll(filepath.Join(projectPath, "feature-x"), 0755)
+	os.MkdirAll(filepath.Join(projectPath, "main"), 0755)
+	t.Setenv("PHOEMUX_TEST_ROOT", projectPath)
+	t.Cleanup(func() {
+		Vars = map[string]string{}
+	})
+	writeAsh(t, "vars", `path: "${PHOEMUX_TEST_ROOT}/{{ .branch }}"
+sessionName: "app-${branch}"
+template: true
+hooks:
+  onKill:
+  - touch killed
+vars:
+  branch: main
+  port: "3000"
+windows:
+- name: server
+  terminals:
+  - command: PORT={{ .port }} npm start -- ${branch} $HOME ${NOT_A_VAR}
+`)
+
+	ash, err := readAsh(GetConfigPath(), "vars")
+	if err != nil {
+		t.Fatalf("failed to read ash: %s", err)
+	}
+	if ash.Path != filepath.Join(projectPath, "main") || ash.SessionName != "app-main" {
+		t.Fatalf("unexpected ash %#v", ash)
+	}
+	if ash.Windows[0].Terminals[0].Command != "PORT=3000 npm start -- main $HOME ${NOT_A_VAR}" {
+		t.Fatalf("unexpected command %q", ash.Windows[0].Terminals[0].Command)
+	}
+
+	Vars["branch"] = "feature-x"
+	ash, err = readAsh(GetConfigPath(), "vars")
+	if err != nil {
+		t.Fatalf("failed to read ash: %s", err)
+	}
+	if ash.Path != filepath.Join(projectPath, "feature-x") || ash.SessionName != "app-feature-x" {
+		t.Fatalf("--set did not override vars %#v", ash)
+	}
+
+	// the vars given with --set are kept for last and the hooks of the session
+	err = Open(GetConfigPath(), "vars")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+	Vars = map[string]string{}
+	err = Kill(GetConfigPath(), "app-feature-x")
+	if err != nil {
+		t.Fatalf("failed to kill session: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "feature-x", "killed")); err != nil {
+		t.Fatalf("onKill hook did not run for the session opened with --set: %s", err)
+	}
+	fake.Current = ""
+	err = OpenLast(GetConfigPath(), 1)
+	if err != nil {
+		t.Fatalf("failed to reopen ash: %s", err)
+	}
+	if _, ok := fake.Sessions["app-feature-x"]; !ok {
+		t.Fatalf("last did not reopen the ash with its vars %v", fake.Sessions)
+	}
+
+	writeAsh(t, "untemplated", fmt.Sprintf(`path: "%s"
+sessionName: "untemplated"
+env:
+  APP: api
+windows:
+- name: server
+  env:
+    PORT: "8080"
+  terminals:
+  - command: serve ${APP} ${PORT} && docker ps --format '{{.Names}}'
+  - command: echo ${PORT}
+    env:
+      PORT: "9090"
+`, projectPath))
+	ash, err = readAsh(GetConfigPath(), "untemplated")
+	if err != nil {
+		t.Fatalf("failed to read ash: %s", err)
+	}
+	if ash.Windows[0].Terminals[0].Command != "serve api 8080 && docker ps --format '{{.Names}}'" {
+		t.Fatalf("unexpected command %q", ash.Windows[0].Terminals[0].Command)
+	}
+	if ash.Windows[0].Terminals[1].Command != "echo 9090" {
+		t.Fatalf("terminal env did not override the window env %q", ash.Windows[0].Terminals[1].Command)
+	}
+
+	writeAsh(t, "missing", fmt.Sprintf(`path: "%s"
+sessionName: "{{ .missing }}"
+template: true
+windows:
+- name: code
+  terminals:
+  - command: ls
+`, projectPath))
+	_, err = readAsh(GetConfigPath(), "missing")
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("expected an error about the missing variable, got %v", err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	phoemuxConfigPath := GetConfigPath()
+	pwd := t.TempDir()
+	os.WriteFile(filepath.Join(pwd, "go.mod"), []byte("module example.com/service\n"), 0666)
+
+	if DetectTemplate(pwd) != "go" {
+		t.Fatalf("expected go template, got %s", DetectTemplate(pwd))
+	}
+	if DetectTemplate(t.TempDir()) != DefaultTemplate {
+		t.Fatal("expected default template for a directory without markers")
+	}
+
+	Create(phoemuxConfigPath, pwd, "detected", CreateOptions{})
+	defer Delete(phoemuxConfigPath, "detected")
+	ash, err := readAsh(phoemuxConfigPath, "detected")
+	if err != nil {
+		t.Fatalf("ash from the go template is not valid: %s", err)
+	}
+	if ash.Path != pwd || ash.Windows[1].Terminals[0].Command != "go test ./..." {
+		t.Fatalf("unexpected ash %#v", ash)
+	}
+
+	for name := range builtinTemplates {
+		Create(phoemuxConfigPath, pwd, "builtin-"+name, CreateOptions{Template: name})
+		err := Validate(phoemuxConfigPath, "builtin-"+name)
+		Delete(phoemuxConfigPath, "builtin-"+name)
+		if err != nil {
+			t.Fatalf("template %s is not valid: %s", name, err)
+		}
+	}
+
+	os.MkdirAll(TemplatesPath(phoemuxConfigPath), 0766)
+	defer os.RemoveAll(TemplatesPath(phoemuxConfigPath))
+	os.WriteFile(filepath.Join(TemplatesPath(phoemuxConfigPath), "go.yaml"), []byte(`# our services
+windows:
+- name: service
+  terminals:
+  - command: make dev
+`), 0666)
+	os.WriteFile(filepath.Join(TemplatesPath(phoemuxConfigPath), "fixed.yaml"), []byte(`path: /tmp
+windows: []
+`), 0666)
+
+	if !slices.Contains(ListTemplates(phoemuxConfigPath), "fixed") {
+		t.Fatalf("user template is not listed: %v", ListTemplates(phoemuxConfigPath))
+	}
+
+	Create(phoemuxConfigPath, pwd, "custom", CreateOptions{Template: "go"})
+	defer Delete(phoemuxConfigPath, "custom")
+	ash, err = readAsh(phoemuxConfigPath, "custom")
+	if err != nil {
+		t.Fatalf("ash from user template is not valid: %s", err)
+	}
+	if ash.Windows[0].Name != "service" {
+		t.Fatalf("user template did not override the built-in one: %#v", ash)
+	}
+
+	for _, template := range []string{"fixed", "missing"} {
+		err := Create(phoemuxConfigPath, pwd, "rejected", CreateOptions{Template: template})
+		if err == nil || ashExist(phoemuxConfigPath, "rejected") {
+			Delete(phoemuxConfigPath, "rejected")
+			t.Fatalf("created an ash from the %s template", template)
+		}
+	}
+}
+
+func TestCreateWithWindows(t *testing.T) {
+	phoemuxConfigPath := GetConfigPath()
+	pwd := t.TempDir()
+	windows, err := ParseWindows([]string{"code:nvim .", "server:make run", "server:make watch", "shell"})
+	if err != nil {
+		t.Fatalf("failed to parse windows: %s", err)
+	}
+
+	err = Create(phoemuxConfigPath, pwd, "flags", CreateOptions{
+		Windows:       windows,
+		DefaultWindow: "server",
+	})
+	if err != nil {
+		t.Fatalf("failed to create ash: %s", err)
+	}
+	defer Delete(phoemuxConfigPath, "flags")
+
+	ash, err := readAsh(phoemuxConfigPath, "flags")
+	if err != nil {
+		t.Fatalf("created ash is not valid: %s", err)
+	}
+	if ash.Path != pwd || ash.DefaultWindow != "server" || len(ash.Windows) != 3 {
+		t.Fatalf("unexpected ash %#v", ash)
+	}
+	if len(ash.Windows[1].Terminals) != 2 || ash.Windows[1].Terminals[1].Command != "make watch" {
+		t.Fatalf("unexpected server window %#v", ash.Windows[1])
+	}
+
+	err = Create(phoemuxConfigPath, pwd, "invalid", CreateOptions{
+		Windows:       windows,
+		DefaultWindow: "missing",
+	})
+	if err == nil || ashExist(phoemuxConfigPath, "invalid") {
+		t.Fatal("created an ash with a missing default window")
+	}
+
+	_, err = ParseWindows([]string{":ls"})
+	if err == nil {
+		t.Fatal("parsed a window without a name")
+	}
+}
+
+func TestRenameAndCopy(t *testing.T) {
+	fake := newFake(t)
+	phoemuxConfigPath := GetConfigPath()
+	projectPath := t.TempDir()
+	writeAsh(t, "old", fmt.Sprintf(`# the api
+path: "%s"
+sessionName: "old" # same as the alias
+windows:
+- name: code
+  terminals:
+  - command: ls
+`, projectPath))
+	writeAsh(t, "child", `extends: old
+sessionName: child
+`)
+	t.Cleanup(func() {
+		os.Remove(ashFilePath(phoemuxConfigPath, "new"))
+		os.Remove(ashFilePath(phoemuxConfigPath, "copied"))
+	})
+
+	err := Open(phoemuxConfigPath, "old")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+
+	err = Rename(phoemuxConfigPath, "old", "new", true)
+	if err != nil {
+		t.Fatalf("failed to rename ash: %s", err)
+	}
+	if ashExist(phoemuxConfigPath, "old") {
+		t.Fatal("old ash still exists")
+	}
+	content, _ := os.ReadFile(ashFilePath(phoemuxConfigPath, "new"))
+	if !strings.Contains(string(content), "# the api") || !strings.Contains(string(content), `sessionName: "new" # same as the alias`) {
+		t.Fatalf("unexpected renamed ash\n%s", content)
+	}
+	if _, ok := fake.Sessions["new"]; !ok || fake.Current != "new" {
+		t.Fatalf("running session was not renamed %#v", fake.Sessions)
+	}
+	history, _ := History(phoemuxConfigPath)
+	if len(history) == 0 || history[0].Alias != "new" || history[0].SessionName != "new" {
+		t.Fatalf("history still points to the old alias %#v", history)
+	}
+	child, err := readAsh(phoemuxConfigPath, "child")
+	if err != nil || child.Extends != "new" {
+		t.Fatalf("extending ash was not updated: %v %#v", err, child)
+	}
+
+	err = Copy(phoemuxConfigPath, "new", "copied")
+	if err != nil {
+		t.Fatalf("failed to copy ash: %s", err)
+	}
+	ash, err := readAsh(phoemuxConfigPath, "copied")
+	if err != nil || ash.SessionName != "copied" {
+		t.Fatalf("unexpected copy %v %#v", err, ash)
+	}
+	if !ashExist(phoemuxConfigPath, "new") {
+		t.Fatal("copy removed the source ash")
+	}
+
+	err = Copy(phoemuxConfigPath, "new", "copied")
+	if err == nil {
+		t.Fatal("copied over an existing ash")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	fake := newFake(t)
+	phoemuxConfigPath := GetConfigPath()
+	ClearHistory(phoemuxConfigPath)
+	t.Cleanup(func() {
+		ClearHistory(phoemuxConfigPath)
+	})
+	for _, alias := range []string{"first", "second", "never"} {
+		writeAsh(t, alias, fmt.Sprintf(`path: "%s"
+sessionName: "%s"
+windows:
+- name: code
+  terminals:
+  - command: ls
+`, t.TempDir(), alias))
+	}
+
+	for _, alias := range []string{"first", "second", "first"} {
+		err := Open(phoemuxConfigPath, alias)
+		if err != nil {
+			t.Fatalf("failed to open %s: %s", alias, err)
+		}
+	}
+
+	entries, err := History(phoemuxConfigPath)
+	if err != nil {
+		t.Fatalf("failed to read history: %s", err)
+	}
+	if len(entries) != 2 || entries[0].Alias != "first" || entries[0].Count != 2 || entries[1].Count != 1 {
+		t.Fatalf("unexpected history %#v", entries)
+	}
+
+	// inside first the session of second is the last one
+	entry, err := lastEntry(phoemuxConfigPath, 1)
+	if err != nil || entry.Alias != "second" {
+		t.Fatalf("expected last to toggle to second, got %s %v", entry.Alias, err)
+	}
+	_, err = lastEntry(phoemuxConfigPath, 2)
+	if err == nil {
+		t.Fatal("expected an error when going back past the history")
+	}
+	fake.Current = ""
+	entry, err = lastEntry(phoemuxConfigPath, 2)
+	if err != nil || entry.Alias != "second" {
+		t.Fatalf("expected second outside of tmux, got %s %v", entry.Alias, err)
+	}
+
+	aliases := []string{"never", "second", "first"}
+	sortByHistory(phoemuxConfigPath, aliases)
+	if !slices.Equal(aliases, []string{"first", "second", "never"}) {
+		t.Fatalf("unexpected order %v", aliases)
+	}
+	err = Open(phoemuxConfigPath, "second")
+	if err != nil {
+		t.Fatalf("failed to open second: %s", err)
+	}
+	sortByHistory(phoemuxConfigPath, aliases)
+	if !slices.Equal(aliases, []string{"second", "first", "never"}) {
+		t.Fatalf("expected the most recent of the ashes opened as often first, got %v", aliases)
+	}
+	for _, alias := range []string{"first", "first", "second"} {
+		err = Open(phoemuxConfigPath, alias)
+		if err != nil {
+			t.Fatalf("failed to open %s: %s", alias, err)
+		}
+	}
+	aliases = []string{"never", "second", "first"}
+	sortByHistory(phoemuxConfigPath, aliases)
+	if !slices.Equal(aliases, []string{"first", "second", "never"}) {
+		t.Fatalf("expected the most opened ash first, got %v", aliases)
+	}
+
+	Delete(phoemuxConfigPath, "first")
+	entries, _ = History(phoemuxConfigPath)
+	if len(entries) != 1 || entries[0].Alias != "second" {
+		t.Fatalf("deleted ash is still in the history %#v", entries)
+	}
+}
+
+func TestListItems(t *testing.T) {
+	fake := newFake(t)
+	projectPath := t.TempDir()
+	writeAsh(t, "listed", fmt.Sprintf(`path: "%s"
+sessionName: "listed"
+windows:
+- name: editor
+  terminals:
+  - command: nvim
+- name: storybook
+  terminals:
+  - command: npm run storybook
+`, projectPath))
+	writeAsh(t, "broken", `path: "/does/not/exist"
+`)
+	fake.Sessions["listed"] = &tmuxtest.Session{Name: "listed"}
+
+	listed := newItem(GetConfigPath(), "listed", Backend.ListSessions())
+	if listed.err != nil || !listed.running || listed.ash.Path != projectPath || len(listed.ash.Windows) != 2 {
+		t.Fatalf("unexpected item %#v", listed)
+	}
+	broken := newItem(GetConfigPath(), "broken", Backend.ListSessions())
+	if broken.err == nil {
+		t.Fatalf("expected broken ash to be invalid %#v", broken)
+	}
+
+	targets := []string{listed.FilterValue(), broken.FilterValue()}
+	for _, term := range []string{"lstd", "strybk", filepath.Base(projectPath)} {
+		ranks := list.DefaultFilter(term, targets)
+		if len(ranks) != 1 || ranks[0].Index != 0 {
+			t.Fatalf("expected %s to only match the listed ash, got %#v", term, ranks)
+		}
+	}
+
+	m := newList([]list.Item{listed, broken}, GetConfigPath(), Config{})
+	var row strings.Builder
+	itemDelegate{}.Render(&row, m.list, 0, listed)
+	for _, expected := range []string{"listed", "running", projectPath, "2 windows"} {
+		if !strings.Contains(row.String(), expected) {
+			t.Fatalf("expected %q in row %q", expected, row.String())
+		}
+	}
+
+	// the edited ash is read again once the editor exits
+	writeAsh(t, "broken", fmt.Sprintf(`path: "%s"
+sessionName: "broken"
+windows:
+- name: code
+  terminals:
+  - command: nvim
+`, projectPath))
+	updated, _ := m.Update(EditorError{alias: "broken"})
+	m = updated.(model)
+	edited := m.list.Items()[1].(item)
+	if edited.err != nil || len(edited.ash.Windows) != 1 {
+		t.Fatalf("expected the edited ash to be read again %#v", edited)
+	}
+	if !m.list.Items()[0].(item).running {
+		t.Fatal("expected the other items to be kept")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	newFake(t)
+	projectPath := t.TempDir()
+	writeAsh(t, "previewed", fmt.Sprintf(`path: "%s"
+sessionName: "previewed"
+defaultWindow: servers
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+- name: servers
+  split: horizontal
+  terminals:
+  - command: make run
+  - command: npm start
+    path: web
+`, projectPath))
+	os.MkdirAll(filepath.Join(projectPath, "web"), 0755)
+
+	description := describeAsh(mustReadAsh(t, "previewed"))
+	for _, expected := range []string{"previewed", "servers (default)", "split horizontal", "nvim .", "in " + filepath.Join(projectPath, "web")} {
+		if !strings.Contains(description, expected) {
+			t.Fatalf("expected %q in preview\n%s", expected, description)
+		}
+	}
+
+	err := Open(GetConfigPath(), "previewed")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+	m := newList([]list.Item{newItem(GetConfigPath(), "previewed", Backend.ListSessions())}, GetConfigPath(), Config{})
+	cmd := m.Init()
+	if cmd == nil {
+		t.Fatal("expected the running session to be captured")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(model)
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m = updated.(model)
+
+	view := m.View()
+	for _, expected := range []string{"active pane", "npm start"} {
+		if !strings.Contains(view, expected) {
+			t.Fatalf("expected %q in view\n%s", expected, view)
+		}
+	}
+}
+
+func mustReadAsh(t *testing.T, alias string) tmux.Ash {
+	t.Helper()
+	ash, err := readAsh(GetConfigPath(), alias)
+	if err != nil {
+		t.Fatalf("failed to read ash %s: %s", alias, err)
+	}
+	return ash
+}
+
+// press sends the keys to the model and returns the command of the last one
+func press(t *testing.T, m model, keys ...string) (model, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			keyMsg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "tab":
+			keyMsg = tea.KeyMsg{Type: tea.KeyTab}
+		case "ctrl+u":
+			keyMsg = tea.KeyMsg{Type: tea.KeyCtrlU}
+		case "esc":
+			keyMsg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		var updated tea.Model
+		updated, cmd = m.Update(keyMsg)
+		m = updated.(model)
+	}
+	return m, cmd
+}
+
+// finish runs the action on a session and hands its result to the model
+func finish(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected an action on the session")
+	}
+	msg, ok := cmd().(sessionActionMsg)
+	if !ok {
+		t.Fatal("expected the result of an action on the session")
+	}
+	if msg.err != nil {
+		t.Fatalf("action on the session failed: %s", msg.err)
+	}
+	updated, _ := m.Update(msg)
+	return updated.(model)
+}
+
+func TestSessionsTab(t *testing.T) {
+	fake := newFake(t)
+	t.Cleanup(func() {
+		SessionChoice = ""
+	})
+	projectPath := t.TempDir()
+	writeAsh(t, "tabbed", fmt.Sprintf(`path: "%s"
+sessionName: "tabbed"
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+`, projectPath))
+
+	err := Open(GetConfigPath(), "tabbed")
+	if err != nil {
+		t.Fatalf("failed to open ash: %s", err)
+	}
+	err = fake.NewSession(tmux.Ash{
+		SessionName: "scratch",
+		Path:        projectPath,
+		Windows:     []tmux.Window{{Name: "shell", Terminals: []tmux.Terminal{{Command: "htop"}}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create session: %s", err)
+	}
+	fake.Current = "scratch"
+
+	items := []list.Item{newItem(GetConfigPath(), "tabbed", Backend.ListSessions())}
+	expected := []list.Item{
+		sessionItem{name: "scratch", current: true},
+		sessionItem{name: "tabbed", alias: "tabbed"},
+	}
+	if sessions := sessionItems(items); !reflect.DeepEqual(sessions, expected) {
+		t.Fatalf("expected sessions %v, got %v", expected, sessions)
+	}
+
+	m := newList(items, GetConfigPath(), Config{})
+	m, _ = press(t, m, "tab")
+	if m.tab != sessionsTab {
+		t.Fatal("expected tab to show the sessions")
+	}
+
+	m, cmd := press(t, m, "x")
+	if len(m.killing) > 0 || cmd != nil {
+		t.Fatal("expected the current session to not be killed")
+	}
+
+	// the session phoemux did not create is renamed and frozen
+	m, cmd = press(t, m, "r", "ctrl+u", "notes", "enter")
+	m = finish(t, m, cmd)
+	if !fake.HasSession("notes") || fake.HasSession("scratch") {
+		t.Fatalf("expected scratch to be renamed, got %v", fake.ListSessions())
+	}
+
+	m, cmd = press(t, m, "f", "ctrl+u", "frozen", "enter")
+	m = finish(t, m, cmd)
+	if !ashExist(GetConfigPath(), "frozen") {
+		t.Fatal("expected the session to be frozen into an ash")
+	}
+	if len(m.list.Items()) != 2 {
+		t.Fatalf("expected the frozen ash in the list, got %v", m.list.Items())
+	}
+
+	m, _ = press(t, m, "j", "x")
+	if !slices.Equal(m.killing, []string{"tabbed"}) {
+		t.Fatalf("expected to confirm the kill of tabbed, got %v", m.killing)
+	}
+	m, cmd = press(t, m, "y")
+	m = finish(t, m, cmd)
+	if !reflect.DeepEqual(fake.Killed, []string{"tabbed"}) {
+		t.Fatalf("expected tabbed to be killed, got %v", fake.Killed)
+	}
+	if m.list.Items()[0].(item).running {
+		t.Fatal("expected the ash to not be running after the kill")
+	}
+
+	m, _ = press(t, m, "enter")
+	if SessionChoice != "notes" || !m.quitting {
+		t.Fatalf("expected to switch to notes, got %q", SessionChoice)
+	}
+}
+
+func TestBatchActions(t *testing.T) {
+	fake := newFake(t)
+	t.Cleanup(func() {
+		Choices = nil
+	})
+	projectPath := t.TempDir()
+	items := []list.Item{}
+	for _, alias := range []string{"first", "second", "third"} {
+		writeAsh(t, alias, fmt.Sprintf(`path: "%s"
+sessionName: "%s"
+hooks:
+  onKill:
+  - echo stopping %s
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+`, projectPath, alias, alias))
+		items = append(items, newItem(GetConfigPath(), alias, Backend.ListSessions()))
+	}
+
+	m := newList(items, GetConfigPath(), Config{})
+	m, _ = press(t, m, " ", "j", "j", " ")
+	if !reflect.DeepEqual(m.marked, map[string]bool{"first": true, "third": true}) {
+		t.Fatalf("expected first and third to be marked, got %v", m.marked)
+	}
+	if !strings.Contains(m.View(), "2 marked") {
+		t.Fatalf("expected the marked count in view\n%s", m.View())
+	}
+
+	m, _ = press(t, m, "enter")
+	if !slices.Equal(Choices, []string{"first", "third"}) || !m.quitting {
+		t.Fatalf("expected to open first and third, got %v", Choices)
+	}
+	err := recreateManyFromAshes(GetConfigPath(), Choices)
+	if err != nil {
+		t.Fatalf("failed to open ashes: %s", err)
+	}
+	if !slices.Equal(fake.ListSessions(), []string{"first", "third"}) || fake.Current != "third" {
+		t.Fatalf("expected to switch to third, got %q of %v", fake.Current, fake.ListSessions())
+	}
+	entries, _ := History(GetConfigPath())
+	if len(entries) == 0 || entries[0].Alias != "third" {
+		t.Fatalf("expected third to be the last opened ash, got %v", entries)
+	}
+
+	m = newList([]list.Item{
+		newItem(GetConfigPath(), "first", Backend.ListSessions()),
+		newItem(GetConfigPath(), "second", Backend.ListSessions()),
+		newItem(GetConfigPath(), "third", Backend.ListSessions()),
+	}, GetConfigPath(), Config{})
+	m, _ = press(t, m, " ", "j", " ", "x")
+	if !slices.Equal(m.killing, []string{"first"}) {
+		t.Fatalf("expected only the running session to be killed, got %v", m.killing)
+	}
+	m, cmd := press(t, m, "y")
+	m = finish(t, m, cmd)
+	if !slices.Equal(fake.Killed, []string{"first"}) {
+		t.Fatalf("expected first to be killed, got %v", fake.Killed)
+	}
+	if !strings.Contains(m.status, "stopping first") {
+		t.Fatalf("expected the output of the onKill hook in the status, got %q", m.status)
+	}
+
+	m, _ = press(t, m, "d")
+	if !strings.Contains(m.View(), "first, second") {
+		t.Fatalf("expected a single confirmation for both ashes\n%s", m.View())
+	}
+	m, cmd = press(t, m, "y")
+	if cmd != nil {
+		updated, _ := m.Update(cmd())
+		m = updated.(model)
+	}
+	if ashExist(GetConfigPath(), "first") || ashExist(GetConfigPath(), "second") || !ashExist(GetConfigPath(), "third") {
+		t.Fatal("expected first and second to be deleted")
+	}
+	if len(m.list.Items()) != 1 || len(m.marked) != 0 {
+		t.Fatalf("expected only third in the list, got %v", m.list.Items())
+	}
+
+	// an ash that fails to be deleted stays in the list
+	os.Remove(ashFilePath(GetConfigPath(), "third"))
+	m, _ = press(t, m, "d", "y")
+	if len(m.list.Items()) != 1 || !strings.Contains(m.status, "third: Ash third does not exist") {
+		t.Fatalf("expected the delete error in the status, got %q", m.status)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	newFake(t)
+	configPath := ConfigPath(GetConfigPath())
+	t.Cleanup(func() {
+		os.Remove(configPath)
+		setTheme(Theme{})
+	})
+
+	config, err := LoadConfig(GetConfigPath())
+	if err != nil || !reflect.DeepEqual(config, Config{}) {
+		t.Fatalf("expected the defaults without config.yaml, got %v %v", config, err)
+	}
+
+	for content, expected := range map[string]string{
+		"keymap: emacs\n":         "keymap emacs must be one of default, vim",
+		"keys:\n  explode: [x]\n": "unknown action explode",
+		"keys:\n  open: []\n":     "action open needs at least one key",
+		"colors: {}\n":            "unknown field",
+		"keys:\n  delete: [e]\n":  "key e is bound to both delete and edit",
+	} {
+		os.WriteFile(configPath, []byte(content), 0666)
+		_, err = LoadConfig(GetConfigPath())
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q for %q, got %v", expected, content, err)
+		}
+	}
+
+	os.WriteFile(configPath, []byte(`keymap: vim
+keys:
+  delete: [D]
+theme:
+  selected: "33"
+`), 0666)
+	config, err = LoadConfig(GetConfigPath())
+	if err != nil {
+		t.Fatalf("failed to load config: %s", err)
+	}
+	for _, keymap := range keymapValues {
+		if err := checkKeys((Config{Keymap: keymap}).keys()); err != nil {
+			t.Fatalf("keys of the %s keymap clash: %s", keymap, err)
+		}
+	}
+	keys := config.keys()
+	if !slices.Equal(keys["delete"], []string{"D"}) || !slices.Equal(keys["open"], []string{"enter", "l"}) || !slices.Equal(keys["edit"], []string{"e"}) {
+		t.Fatalf("expected vim keys with delete remapped, got %v", keys)
+	}
+
+	setTheme(config.Theme)
+	if selectedItemStyle.GetForeground() != lipgloss.Color("33") || warningTextStyle.GetForeground() != lipgloss.Color(defaultTheme.Warning) {
+		t.Fatal("expected the selected color to be replaced and the others to be kept")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if !(Theme{}).noColor() {
+		t.Fatal("expected NO_COLOR to drop the colors")
+	}
+
+	// config.yaml is not an ash and its alias can not be taken
+	ashes, _ := GetSimpleList(GetConfigPath())
+	if slices.Contains(ashes, "config") {
+		t.Fatalf("expected config.yaml to not be listed, got %v", ashes)
+	}
+	OpenEditor = false
+	defer func() { OpenEditor = true }()
+	err = Create(GetConfigPath(), t.TempDir(), "config", CreateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Fatalf("expected config to be a reserved alias, got %v", err)
+	}
+
+	writeAsh(t, "remapped", fmt.Sprintf(`path: "%s"
+sessionName: "remapped"
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+`, t.TempDir()))
+	m := newList([]list.Item{newItem(GetConfigPath(), "remapped", Backend.ListSessions())}, GetConfigPath(), config)
+	plain := newList([]list.Item{}, GetConfigPath(), Config{})
+	if slices.Contains(plain.list.KeyMap.NextPage.Keys(), "d") || slices.Contains(plain.list.KeyMap.NextPage.Keys(), "f") {
+		t.Fatalf("expected d and f to be left to delete and freeze, got %v", plain.list.KeyMap.NextPage.Keys())
+	}
+	m, _ = press(t, m, "d")
+	if len(m.deleting) > 0 {
+		t.Fatal("expected d to no longer delete")
+	}
+	m, _ = press(t, m, "D")
+	if !slices.Equal(m.deleting, []string{"remapped"}) {
+		t.Fatalf("expected D to delete, got %v", m.deleting)
+	}
+	m, _ = press(t, m, "esc")
+	if len(m.deleting) > 0 {
+		t.Fatal("expected esc to cancel in the vim keymap")
+	}
+	m, _ = press(t, m, "l")
+	if m.choice != "remapped" {
+		t.Fatalf("expected l to open the ash, got %q", m.choice)
+	}
+	Choice = ""
 }
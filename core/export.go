@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+var ExportFormats = []string{"sh"}
+
+// shellRoot is the first line of the script, the paths under the path of the ash are relative to
+// the directory of the script unless PHOEMUX_ROOT points somewhere else
+const shellRoot = `ROOT="${PHOEMUX_ROOT:-$(cd "$(dirname "$0")" && pwd)}"`
+
+// rootedWord quotes the value, a path under root starts from $ROOT so the script works
+// wherever the project is checked out
+func rootedWord(root, value string) string {
+	relative, err := filepath.Rel(root, value)
+	if err != nil || !filepath.IsAbs(value) || relative == ".." || strings.HasPrefix(relative, "../") {
+		return tmux.Quote(value)
+	}
+	if relative == "." {
+		return `"$ROOT"`
+	}
+	return `"$ROOT"/` + tmux.Quote(relative)
+}
+
+// shellCommandLine is tmux.CommandLine with the paths under root relative to $ROOT
+func shellCommandLine(root string, args []string) string {
+	words := []string{"tmux"}
+	for _, arg := range args {
+		words = append(words, rootedWord(root, arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellHooks runs the hooks like runHooks does, a failing hook is reported but does not stop the script
+func shellHooks(ash tmux.Ash, commands []string, indent string) string {
+	var script strings.Builder
+	keys := []string{}
+	for key := range ash.Env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, command := range commands {
+		prefix := "cd " + rootedWord(ash.Path, ash.Path)
+		for _, key := range keys {
+			prefix += " && export " + tmux.Quote(key+"="+ash.Env[key])
+		}
+		fmt.Fprintf(
+			&script,
+			"%s(%s && %s) || echo %s >&2\n",
+			indent,
+			prefix,
+			command,
+			tmux.Quote(fmt.Sprintf("hook %q failed", command)),
+		)
+	}
+	return script.String()
+}
+
+// exportShell renders the tmux commands that open the ash as a POSIX shell script
+func exportShell(alias string, ash tmux.Ash) (string, error) {
+	recorder := &tmux.Recorder{}
+	err := buildSession(recorder, ash)
+	if err != nil {
+		return "", err
+	}
+
+	var script strings.Builder
+	fmt.Fprintf(&script, "#!/bin/sh\n")
+	fmt.Fprintf(&script, "# opens the %s workspace, generated by phoemux export\n", alias)
+	fmt.Fprintf(&script, "set -e\n")
+	fmt.Fprintf(&script, "%s\n\n", shellRoot)
+
+	fmt.Fprintf(&script, "if ! %s 2>/dev/null; then\n", tmux.CommandLine(tmux.HasSessionArgs(ash.SessionName)))
+	for _, args := range recorder.Commands {
+		fmt.Fprintf(&script, "\t%s\n", shellCommandLine(ash.Path, args))
+	}
+	script.WriteString(shellHooks(ash, ash.Hooks.OnCreate, "\t"))
+	if len(ash.Hooks.OnAttach) > 0 {
+		fmt.Fprintf(&script, "else\n")
+		script.WriteString(shellHooks(ash, ash.Hooks.OnAttach, "\t"))
+	}
+	fmt.Fprintf(&script, "fi\n\n")
+
+	fmt.Fprintf(&script, "if [ -n \"$TMUX\" ]; then\n")
+	fmt.Fprintf(&script, "\t%s\n", tmux.CommandLine(tmux.SwitchSessionArgs(ash.SessionName)))
+	fmt.Fprintf(&script, "else\n")
+	fmt.Fprintf(&script, "\t%s\n", tmux.CommandLine(tmux.AttachArgs(ash)))
+	fmt.Fprintf(&script, "fi\n")
+
+	return script.String(), nil
+}
+
+// Export renders the ash in the format, sh is a script that runs the same
+// tmux commands phoemux runs to open the ash
+func Export(phoemuxConfigPath, alias, format string) (string, error) {
+	ash, err := readAsh(phoemuxConfigPath, alias)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case "sh":
+		return exportShell(alias, ash)
+	}
+	return "", fmt.Errorf("unknown format %s, expected one of %s", format, strings.Join(ExportFormats, ", "))
+}
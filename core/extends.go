@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/goccy/go-yaml"
+)
+
+// extendAsh merges the ash on top of the ash it extends, chain holds the files
+// already visited so an ash can not end up extending itself. The files of the extended
+// ashes are returned from the closest one
+func extendAsh(phoemuxConfigPath string, ash tmux.Ash, chain []string) (tmux.Ash, []ashSource, error) {
+	filePath := ashFilePath(phoemuxConfigPath, ash.Extends)
+	if slices.Contains(chain, filePath) {
+		return ash, nil, fmt.Errorf(
+			"ash extends itself through %s",
+			strings.Join(append(chain, filePath), " -> "),
+		)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return ash, nil, fmt.Errorf("failed to read extended ash %s: %w", ash.Extends, err)
+	}
+
+	var base tmux.Ash
+	err = yaml.UnmarshalWithOptions(content, &base, yaml.Strict())
+	if err != nil {
+		return ash, nil, fmt.Errorf("%s %s", filePath, yaml.FormatError(err, false, true))
+	}
+
+	sources := []ashSource{newAshSource(filePath, content, base)}
+	if base.Extends != "" {
+		var bases []ashSource
+		base, bases, err = extendAsh(phoemuxConfigPath, base, append(chain, filePath))
+		if err != nil {
+			return ash, nil, err
+		}
+		sources = append(sources, bases...)
+	}
+
+	return mergeAsh(base, ash), sources, nil
+}
+
+func mergeEnv(base, env map[string]string) map[string]string {
+	if len(base) == 0 && len(env) == 0 {
+		return nil
+	}
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, env)
+	return merged
+}
+
+func override(base, value string) string {
+	if value != "" {
+		return value
+	}
+	return base
+}
+
+// mergeWindow overrides the fields the window sets, the terminals are replaced as a whole
+func mergeWindow(base, window tmux.Window) tmux.Window {
+	merged := base
+	merged.Split = override(base.Split, window.Split)
+	merged.Layout = override(base.Layout, window.Layout)
+	merged.Path = override(base.Path, window.Path)
+	merged.Env = mergeEnv(base.Env, window.Env)
+	if len(window.Terminals) > 0 {
+		merged.Terminals = window.Terminals
+	}
+	return merged
+}
+
+// mergeAsh applies the ash on top of its base: the values it sets win, env and vars keys are
+// overridden, hooks run after the ones of the base, windows with the name of a base
+// window override it in place and the rest are appended
+func mergeAsh(base, ash tmux.Ash) tmux.Ash {
+	merged := tmux.Ash{
+		Extends:       ash.Extends,
+		Path:          override(base.Path, ash.Path),
+		SessionName:   override(base.SessionName, ash.SessionName),
+		Vars:          mergeEnv(base.Vars, ash.Vars),
+		Template:      base.Template || ash.Template,
+		Env:           mergeEnv(base.Env, ash.Env),
+		DefaultWindow: override(base.DefaultWindow, ash.DefaultWindow),
+		Hooks: tmux.Hooks{
+			OnCreate: slices.Concat(base.Hooks.OnCreate, ash.Hooks.OnCreate),
+			OnAttach: slices.Concat(base.Hooks.OnAttach, ash.Hooks.OnAttach),
+			OnKill:   slices.Concat(base.Hooks.OnKill, ash.Hooks.OnKill),
+		},
+		Windows: slices.Clone(base.Windows),
+	}
+
+	for _, window := range ash.Windows {
+		i := slices.IndexFunc(merged.Windows, func(w tmux.Window) bool {
+			return w.Name == window.Name
+		})
+		if i == -1 {
+			merged.Windows = append(merged.Windows, window)
+			continue
+		}
+		merged.Windows[i] = mergeWindow(merged.Windows[i], window)
+	}
+
+	return merged
+}
+
+// extendedAliases returns the aliases that other ashes extend
+func extendedAliases(phoemuxConfigPath string, ashes []string) []string {
+	bases := []string{}
+	for _, alias := range ashes {
+		content, err := os.ReadFile(ashFilePath(phoemuxConfigPath, alias))
+		if err != nil {
+			continue
+		}
+		var ash tmux.Ash
+		if yaml.Unmarshal(content, &ash) != nil || ash.Extends == "" {
+			continue
+		}
+		bases = append(bases, ash.Extends)
+	}
+	return bases
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/goccy/go-yaml"
+)
+
+// relativePath returns path relative to base when it is inside of it
+func relativePath(base, path string) string {
+	if path == base {
+		return ""
+	}
+	relative, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return path
+	}
+	return relative
+}
+
+// windowName turns the name of a tmux window into one an ash accepts, tmux names windows
+// after their command so sessions made by hand repeat names like zsh or use dots like python3.11
+func windowName(name string, taken []string) string {
+	name = strings.NewReplacer(":", "-", ".", "-").Replace(name)
+	if name == "" {
+		name = "window"
+	}
+	unique := name
+	for n := 2; slices.Contains(taken, unique); n++ {
+		unique = fmt.Sprintf("%s-%d", name, n)
+	}
+	return unique
+}
+
+// ashFromSession builds an ash that recreates the windows and panes of a running session
+func ashFromSession(sessionName string, windows []tmux.WindowInfo) tmux.Ash {
+	ash := tmux.Ash{
+		SessionName: sessionName,
+	}
+	if len(windows) > 0 && len(windows[0].Panes) > 0 {
+		ash.Path = windows[0].Panes[0].Path
+	}
+
+	names := []string{}
+	for _, info := range windows {
+		window := tmux.Window{
+			Name: windowName(info.Name, names),
+		}
+		names = append(names, window.Name)
+		if info.Active {
+			ash.DefaultWindow = window.Name
+		}
+		if len(info.Panes) > 0 {
+			window.Path = relativePath(ash.Path, info.Panes[0].Path)
+		}
+		if len(info.Panes) > 1 {
+			window.Layout = info.Layout
+		}
+
+		windowPath := tmux.WindowPath(ash, window)
+		for _, pane := range info.Panes {
+			terminal := tmux.Terminal{
+				Command: pane.Command,
+			}
+			if pane.Path != windowPath {
+				terminal.Path = relativePath(ash.Path, pane.Path)
+			}
+			window.Terminals = append(window.Terminals, terminal)
+		}
+		ash.Windows = append(ash.Windows, window)
+	}
+
+	return ash
+}
+
+// writeNewAsh stores the ash under the alias, failing if the alias is taken
+func writeNewAsh(phoemuxConfigPath, alias string, ash tmux.Ash) error {
+	if err := reservedAlias(alias); err != nil {
+		return err
+	}
+
+	filePath := fmt.Sprintf(
+		"%s/%s.yaml",
+		phoemuxConfigPath,
+		alias,
+	)
+
+	if fileExist(filePath) {
+		return fmt.Errorf("ash for %s already exist", alias)
+	}
+
+	content, err := yaml.Marshal(ash)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ash: %w", err)
+	}
+
+	err = WriteSchema(phoemuxConfigPath)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+
+	err = os.WriteFile(filePath, append([]byte(schemaHeader(phoemuxConfigPath)), content...), 0666)
+	if err != nil {
+		return fmt.Errorf("Failed to write ash: %w", err)
+	}
+
+	return nil
+}
+
+// Freeze writes a new ash that recreates the running session
+func Freeze(phoemuxConfigPath, alias, sessionName string) error {
+	if alias == "" {
+		return fmt.Errorf("freeze command expects an alias")
+	}
+
+	if ashExist(phoemuxConfigPath, alias) {
+		return fmt.Errorf("ash for %s already exist", alias)
+	}
+
+	windows, err := Backend.DescribeSession(sessionName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect session %s: %w", sessionName, err)
+	}
+
+	ash := ashFromSession(sessionName, windows)
+	content, err := yaml.Marshal(ash)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ash: %w", err)
+	}
+	// the ash is checked before it is written so it can open the session it was frozen from
+	_, err = parseAsh(phoemuxConfigPath, ashFilePath(phoemuxConfigPath, alias), "", content)
+	if err != nil {
+		return fmt.Errorf("session %s can not be frozen: %w", sessionName, err)
+	}
+
+	return writeNewAsh(phoemuxConfigPath, alias, ash)
+}
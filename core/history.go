@@ -0,0 +1,202 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	historyFileName = "history.json"
+	// older entries are dropped so the file does not grow forever
+	maxHistory = 100
+)
+
+// HistoryEntry records how often and how recently an ash was opened, local
+// ashes use the path of their file as alias
+type HistoryEntry struct {
+	Alias       string    `json:"alias"`
+	SessionName string    `json:"sessionName"`
+	LastOpened  time.Time `json:"lastOpened"`
+	Count       int       `json:"count"`
+	// Vars are the values given with --set the last time the ash was opened
+	Vars map[string]string `json:"vars,omitempty"`
+}
+
+func historyPath(phoemuxConfigPath string) string {
+	return filepath.Join(phoemuxConfigPath, historyFileName)
+}
+
+// History returns the opened ashes, most recent first. Before the history
+// existed only the last alias was stored in the cache file, it is used as
+// the first entry when there is no history yet
+func History(phoemuxConfigPath string) ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+
+	content, err := os.ReadFile(historyPath(phoemuxConfigPath))
+	if os.IsNotExist(err) {
+		cache, err := os.ReadFile(filepath.Join(phoemuxConfigPath, "cache"))
+		if err == nil && len(cache) > 0 {
+			entries = append(entries, HistoryEntry{Alias: string(cache), Count: 1})
+		}
+		return entries, nil
+	}
+	if err != nil {
+		return entries, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	err = json.Unmarshal(content, &entries)
+	if err != nil {
+		return entries, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return entries, nil
+}
+
+func writeHistory(phoemuxConfigPath string, entries []HistoryEntry) error {
+	if len(entries) > maxHistory {
+		entries = entries[:maxHistory]
+	}
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+	err = os.WriteFile(historyPath(phoemuxConfigPath), content, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+func historyIndex(entries []HistoryEntry, alias string) int {
+	return slices.IndexFunc(entries, func(entry HistoryEntry) bool {
+		return entry.Alias == alias
+	})
+}
+
+// recordOpen moves the ash to the top of the history along with the vars given with --set,
+// failing to do so does not stop it from opening
+func recordOpen(phoemuxConfigPath, alias, sessionName string) {
+	entries, err := History(phoemuxConfigPath)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+
+	entry := HistoryEntry{Alias: alias}
+	if i := historyIndex(entries, alias); i != -1 {
+		entry = entries[i]
+		entries = slices.Delete(entries, i, i+1)
+	}
+	entry.SessionName = sessionName
+	entry.LastOpened = time.Now()
+	entry.Count++
+	entry.Vars = nil
+	if len(Vars) > 0 {
+		entry.Vars = maps.Clone(Vars)
+	}
+
+	err = writeHistory(phoemuxConfigPath, slices.Insert(entries, 0, entry))
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+}
+
+// renameInHistory keeps the entry of a renamed ash, sessionName is empty when it did not change
+func renameInHistory(phoemuxConfigPath, oldAlias, newAlias, sessionName string) error {
+	entries, err := History(phoemuxConfigPath)
+	if err != nil {
+		return err
+	}
+	i := historyIndex(entries, oldAlias)
+	if i == -1 {
+		return nil
+	}
+	entries[i].Alias = newAlias
+	if sessionName != "" {
+		entries[i].SessionName = sessionName
+	}
+	return writeHistory(phoemuxConfigPath, entries)
+}
+
+func removeFromHistory(phoemuxConfigPath, alias string) error {
+	entries, err := History(phoemuxConfigPath)
+	if err != nil {
+		return err
+	}
+	i := historyIndex(entries, alias)
+	if i == -1 {
+		return nil
+	}
+	return writeHistory(phoemuxConfigPath, slices.Delete(entries, i, i+1))
+}
+
+// lastEntry returns the nth most recently opened ash, starting at 1. The ash of the
+// session phoemux runs in is skipped so last toggles between the two latest ashes
+func lastEntry(phoemuxConfigPath string, n int) (HistoryEntry, error) {
+	if n < 1 {
+		return HistoryEntry{}, fmt.Errorf("expected a position of 1 or more, got %d", n)
+	}
+	entries, err := History(phoemuxConfigPath)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+
+	current := Backend.CurrentSession()
+	candidates := slices.DeleteFunc(entries, func(entry HistoryEntry) bool {
+		return current != "" && entry.SessionName == current
+	})
+	if len(candidates) == 0 {
+		return HistoryEntry{}, fmt.Errorf("no ash was opened yet")
+	}
+	if n > len(candidates) {
+		return HistoryEntry{}, fmt.Errorf("only %d ashes in the history", len(candidates))
+	}
+	return candidates[n-1], nil
+}
+
+// restoreVars sets the vars the entry was opened with, the ones given with --set win
+func restoreVars(entry HistoryEntry) {
+	for key, value := range entry.Vars {
+		if _, set := Vars[key]; !set {
+			Vars[key] = value
+		}
+	}
+}
+
+// sortByHistory orders the aliases from the most often opened one, the most recently
+// opened one goes first among the ones opened as often and the ones that were never
+// opened keep their order at the end
+func sortByHistory(phoemuxConfigPath string, aliases []string) {
+	entries, err := History(phoemuxConfigPath)
+	if err != nil {
+		return
+	}
+	rank := func(alias string) (int, int) {
+		i := historyIndex(entries, alias)
+		if i == -1 {
+			return 0, len(entries)
+		}
+		return entries[i].Count, i
+	}
+	slices.SortStableFunc(aliases, func(a, b string) int {
+		countA, recentA := rank(a)
+		countB, recentB := rank(b)
+		if countA != countB {
+			return countB - countA
+		}
+		return recentA - recentB
+	})
+}
+
+// ClearHistory forgets every opened ash
+func ClearHistory(phoemuxConfigPath string) error {
+	os.Remove(filepath.Join(phoemuxConfigPath, "cache"))
+	err := os.Remove(historyPath(phoemuxConfigPath))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+// runHooks runs each command with sh from the ash path and with the ash environment,
+// a failing hook is reported but does not stop the rest
+func runHooks(ash tmux.Ash, commands []string) {
+	runHooksWith(ash, commands, os.Stdin, os.Stdout, os.Stderr)
+}
+
+// captureHooks runs the hooks like runHooks without a terminal and returns what they printed,
+// the list owns the terminal while it is shown so the hooks it runs can not use it
+func captureHooks(ash tmux.Ash, commands []string) string {
+	var output strings.Builder
+	runHooksWith(ash, commands, nil, &output, &output)
+	return output.String()
+}
+
+func runHooksWith(ash tmux.Ash, commands []string, stdin io.Reader, stdout, stderr io.Writer) {
+	for _, command := range commands {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = ash.Path
+		cmd.Env = os.Environ()
+		for key, value := range ash.Env {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+		}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.Stdin = stdin
+		err := cmd.Run()
+		if err != nil {
+			fmt.Fprintf(stdout, "hook %q failed: %s\n", command, err)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/goccy/go-yaml"
+)
+
+var ImportFormats = []string{"tmuxinator", "tmuxp"}
+
+// tmuxinatorProject is a tmuxinator project file, fields that may be a string or
+// a list of strings are kept as any
+type tmuxinatorProject struct {
+	Name                string           `yaml:"name"`
+	Root                string           `yaml:"root"`
+	ProjectRoot         string           `yaml:"project_root"`
+	Pre                 any              `yaml:"pre"`
+	PreWindow           any              `yaml:"pre_window"`
+	OnProjectStart      any              `yaml:"on_project_start"`
+	OnProjectFirstStart any              `yaml:"on_project_first_start"`
+	OnProjectRestart    any              `yaml:"on_project_restart"`
+	OnProjectStop       any              `yaml:"on_project_stop"`
+	StartupWindow       any              `yaml:"startup_window"`
+	Windows             []map[string]any `yaml:"windows"`
+	Tabs                []map[string]any `yaml:"tabs"`
+}
+
+type tmuxpWindow struct {
+	WindowName         string            `yaml:"window_name"`
+	Layout             string            `yaml:"layout"`
+	StartDirectory     string            `yaml:"start_directory"`
+	ShellCommandBefore any               `yaml:"shell_command_before"`
+	Environment        map[string]string `yaml:"environment"`
+	Focus              any               `yaml:"focus"`
+	Panes              []any             `yaml:"panes"`
+}
+
+type tmuxpSession struct {
+	SessionName        string            `yaml:"session_name"`
+	StartDirectory     string            `yaml:"start_directory"`
+	BeforeScript       string            `yaml:"before_script"`
+	ShellCommandBefore any               `yaml:"shell_command_before"`
+	Environment        map[string]string `yaml:"environment"`
+	Windows            []tmuxpWindow     `yaml:"windows"`
+}
+
+// stringList turns a value that may be a string, a list or nothing into a list of commands
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{}
+	case []any:
+		commands := []string{}
+		for _, command := range v {
+			commands = append(commands, stringList(command)...)
+		}
+		return commands
+	case map[string]any:
+		// named pane, the name is followed by its commands
+		commands := []string{}
+		for _, command := range v {
+			commands = append(commands, stringList(command)...)
+		}
+		return commands
+	default:
+		command := fmt.Sprint(v)
+		if command == "" {
+			return []string{}
+		}
+		return []string{command}
+	}
+}
+
+// joinCommands runs the commands one after the other in the same pane
+func joinCommands(before []string, commands []string) string {
+	if len(commands) == 0 {
+		return ""
+	}
+	return strings.Join(append(append([]string{}, before...), commands...), "; ")
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+func isTrue(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func fromTmuxinator(content []byte) (tmux.Ash, error) {
+	var project tmuxinatorProject
+	err := yaml.Unmarshal(content, &project)
+	if err != nil {
+		return tmux.Ash{}, fmt.Errorf("failed to parse tmuxinator project: %s", yaml.FormatError(err, false, true))
+	}
+
+	root := project.Root
+	if root == "" {
+		root = project.ProjectRoot
+	}
+	ash := tmux.Ash{
+		Path:        expandHome(root),
+		SessionName: project.Name,
+		Hooks: tmux.Hooks{
+			OnCreate: append(
+				append(stringList(project.OnProjectStart), stringList(project.OnProjectFirstStart)...),
+				stringList(project.Pre)...,
+			),
+			OnAttach: stringList(project.OnProjectRestart),
+			OnKill:   stringList(project.OnProjectStop),
+		},
+	}
+	preWindow := stringList(project.PreWindow)
+
+	windows := project.Windows
+	if len(windows) == 0 {
+		windows = project.Tabs
+	}
+	for _, definition := range windows {
+		for name, value := range definition {
+			window := tmux.Window{
+				Name: name,
+			}
+
+			// a map holds the options of the window, panes included, anything else its commands
+			options, isMap := value.(map[string]any)
+			if !isMap {
+				// the window runs its commands in a single pane
+				window.Terminals = []tmux.Terminal{{
+					Command: joinCommands(preWindow, stringList(value)),
+				}}
+				ash.Windows = append(ash.Windows, window)
+				continue
+			}
+
+			if root, ok := options["root"].(string); ok {
+				window.Path = expandHome(root)
+			}
+			if layout, ok := options["layout"].(string); ok {
+				window.Layout = layout
+			}
+			before := append(append([]string{}, preWindow...), stringList(options["pre"])...)
+			panes, _ := options["panes"].([]any)
+			for _, pane := range panes {
+				window.Terminals = append(window.Terminals, tmux.Terminal{
+					Command: joinCommands(before, stringList(pane)),
+				})
+			}
+			if len(window.Terminals) == 0 {
+				window.Terminals = []tmux.Terminal{{}}
+			}
+			ash.Windows = append(ash.Windows, window)
+		}
+	}
+
+	switch startup := project.StartupWindow.(type) {
+	case string:
+		ash.DefaultWindow = startup
+	case uint64:
+		if int(startup) < len(ash.Windows) {
+			ash.DefaultWindow = ash.Windows[startup].Name
+		}
+	}
+
+	return ash, nil
+}
+
+// tmuxpPaneCommands returns the commands of a tmuxp pane, which can be a command,
+// a list of commands, an object with shell_command or blank for an empty pane
+func tmuxpPaneCommands(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "blank" || v == "pane" {
+			return []string{}
+		}
+	case map[string]any:
+		return stringList(v["shell_command"])
+	}
+	return stringList(value)
+}
+
+func fromTmuxp(content []byte) (tmux.Ash, error) {
+	var session tmuxpSession
+	err := yaml.Unmarshal(content, &session)
+	if err != nil {
+		return tmux.Ash{}, fmt.Errorf("failed to parse tmuxp session: %s", yaml.FormatError(err, false, true))
+	}
+
+	ash := tmux.Ash{
+		Path:        expandHome(session.StartDirectory),
+		SessionName: session.SessionName,
+		Env:         session.Environment,
+	}
+	if session.BeforeScript != "" {
+		ash.Hooks.OnCreate = []string{session.BeforeScript}
+	}
+	sessionBefore := stringList(session.ShellCommandBefore)
+
+	for _, definition := range session.Windows {
+		window := tmux.Window{
+			Name:   definition.WindowName,
+			Layout: definition.Layout,
+			Path:   expandHome(definition.StartDirectory),
+			Env:    definition.Environment,
+		}
+		if isTrue(definition.Focus) {
+			ash.DefaultWindow = window.Name
+		}
+		before := append(append([]string{}, sessionBefore...), stringList(definition.ShellCommandBefore)...)
+
+		for _, value := range definition.Panes {
+			terminal := tmux.Terminal{
+				Command: joinCommands(before, tmuxpPaneCommands(value)),
+			}
+			if options, ok := value.(map[string]any); ok {
+				if directory, ok := options["start_directory"].(string); ok {
+					terminal.Path = expandHome(directory)
+				}
+				if environment, ok := options["environment"].(map[string]any); ok {
+					terminal.Env = map[string]string{}
+					for key, value := range environment {
+						terminal.Env[key] = fmt.Sprint(value)
+					}
+				}
+			}
+			window.Terminals = append(window.Terminals, terminal)
+		}
+		if len(window.Terminals) == 0 {
+			window.Terminals = []tmux.Terminal{{}}
+		}
+		ash.Windows = append(ash.Windows, window)
+	}
+
+	return ash, nil
+}
+
+// Import converts a tmuxinator or tmuxp project file into a new ash, when alias is empty
+// the session name of the project is used
+func Import(phoemuxConfigPath, format, projectPath, alias string) (string, error) {
+	content, err := os.ReadFile(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", projectPath, err)
+	}
+
+	var ash tmux.Ash
+	switch format {
+	case "tmuxinator":
+		ash, err = fromTmuxinator(content)
+	case "tmuxp":
+		ash, err = fromTmuxp(content)
+	default:
+		return "", fmt.Errorf("unknown format %s, expected one of %s", format, strings.Join(ImportFormats, ", "))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if alias == "" {
+		alias = ash.SessionName
+	}
+	if alias == "" {
+		alias = strings.TrimSuffix(filepath.Base(projectPath), filepath.Ext(projectPath))
+	}
+	if ash.SessionName == "" {
+		ash.SessionName = alias
+	}
+
+	return alias, writeNewAsh(phoemuxConfigPath, alias, ash)
+}
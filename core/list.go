@@ -1,13 +1,18 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
+	"slices"
 	"strings"
 
+	"github.com/jhonnyV-V/phoemux/tmux"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -23,49 +28,129 @@ var (
 			Bold(true).
 			Border(lipgloss.ThickBorder(), false, false, true)
 	itemStyle         = lipgloss.NewStyle().PaddingLeft(4)
-	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170"))
+	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color(defaultTheme.Selected))
 	paginationStyle   = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
 	helpStyle         = list.DefaultStyles().HelpStyle.PaddingLeft(4).PaddingBottom(1)
 	quitTextStyle     = lipgloss.NewStyle().Margin(1, 0, 2, 4)
-	warningTextStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
+	warningTextStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Warning)).Bold(true)
 	boldTextStyle     = lipgloss.NewStyle().Bold(true)
-	keywordStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("204")).Background(lipgloss.Color("235"))
+	keywordStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Keyword)).Background(lipgloss.Color(defaultTheme.KeywordBackground))
+	detailStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Detail))
+	runningStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Running))
+	tabStyle          = lipgloss.NewStyle().Padding(0, 2).Foreground(lipgloss.Color(defaultTheme.Detail))
+	activeTabStyle    = tabStyle.Foreground(lipgloss.Color(defaultTheme.Selected)).Bold(true).Underline(true)
+	markedStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color(defaultTheme.Marked)).Bold(true)
 )
 
+// EditorError is sent when the editor of an ash exits
 type EditorError struct {
-	err error
+	alias string
+	err   error
 }
 
 type listKeyMap struct {
+	quit            key.Binding
+	switchTab       key.Binding
 	editSelection   key.Binding
 	deleteSelection key.Binding
 	openSelection   key.Binding
+	markSelection   key.Binding
+	killSelection   key.Binding
+	switchSession   key.Binding
+	killSession     key.Binding
+	renameSession   key.Binding
+	freezeSession   key.Binding
+	confirm         key.Binding
+	cancel          key.Binding
 }
 
-func newListKeyMap() *listKeyMap {
+// newListKeyMap creates the bindings of the actions from the keys resolved by the config
+func newListKeyMap(keys map[string][]string) *listKeyMap {
 	return &listKeyMap{
-		editSelection: key.NewBinding(
-			key.WithKeys("e"),
-			key.WithHelp("e", "edit ash"),
-		),
-		deleteSelection: key.NewBinding(
-			key.WithKeys("d"),
-			key.WithHelp("d", "delete ash"),
-		),
-		openSelection: key.NewBinding(
-			key.WithKeys("enter"),
-			key.WithHelp("enter", "open ash"),
-		),
+		quit:            binding(keys["quit"], "quit"),
+		switchTab:       binding(keys["tab"], "ashes/sessions"),
+		editSelection:   binding(keys["edit"], "edit ash"),
+		deleteSelection: binding(keys["delete"], "delete ash"),
+		openSelection:   binding(keys["open"], "open ash"),
+		markSelection:   binding(keys["mark"], "mark ash"),
+		killSelection:   binding(keys["kill"], "kill sessions"),
+		switchSession:   binding(keys["open"], "switch to session"),
+		killSession:     binding(keys["kill"], "kill session"),
+		renameSession:   binding(keys["rename"], "rename session"),
+		freezeSession:   binding(keys["freeze"], "freeze into an ash"),
+		confirm:         binding(keys["confirm"], "confirm"),
+		cancel:          binding(keys["cancel"], "cancel"),
 	}
 }
 
-type item string
+// item is an ash in the list along with what is shown about it
+type item struct {
+	alias string
+	ash   tmux.Ash
+	//invalid ashes are listed so they can be edited or deleted
+	err     error
+	running bool
+	//bases are only extended by other ashes, they are listed to be edited but can not be opened
+	base bool
+}
 
-func (i item) FilterValue() string { return "" }
+// newItem reads the ash of the alias, sessions are the running sessions
+// listed once for every item
+func newItem(phoemuxConfigPath, alias string, sessions []string) item {
+	ash, err := readAsh(phoemuxConfigPath, alias)
+	if err != nil {
+		return item{alias: alias, err: err}
+	}
+	return item{
+		alias:   alias,
+		ash:     ash,
+		running: slices.Contains(sessions, ash.SessionName),
+	}
+}
 
-type itemDelegate struct{}
+// newBaseItem lists an ash that other ashes extend, it is checked the way validate checks it
+func newBaseItem(phoemuxConfigPath, alias string) item {
+	ash, err := readBase(phoemuxConfigPath, alias)
+	return item{alias: alias, ash: ash, err: err, base: true}
+}
 
-func (d itemDelegate) Height() int                             { return 1 }
+// newListItem lists the alias as a base when it is extended by one of bases and partial, see partialAsh
+func newListItem(phoemuxConfigPath, alias string, bases, sessions []string) item {
+	if slices.Contains(bases, alias) && partialAsh(phoemuxConfigPath, alias) {
+		return newBaseItem(phoemuxConfigPath, alias)
+	}
+	return newItem(phoemuxConfigPath, alias, sessions)
+}
+
+// FilterValue lets the fuzzy filter match the alias, the path and the window names
+func (i item) FilterValue() string {
+	values := []string{i.alias, i.ash.Path}
+	for _, window := range i.ash.Windows {
+		values = append(values, window.Name)
+	}
+	return strings.Join(values, " ")
+}
+
+func (i item) details() string {
+	if i.err != nil {
+		return warningTextStyle.Render("invalid ash, run phoemux validate " + i.alias)
+	}
+	windows := "windows"
+	if len(i.ash.Windows) == 1 {
+		windows = "window"
+	}
+	if i.base {
+		return detailStyle.Render(fmt.Sprintf("base of other ashes · %d %s", len(i.ash.Windows), windows))
+	}
+	return detailStyle.Render(fmt.Sprintf("%s · %d %s", i.ash.Path, len(i.ash.Windows), windows))
+}
+
+type itemDelegate struct {
+	//aliases marked for a batch action, shared with the model
+	marked map[string]bool
+}
+
+func (d itemDelegate) Height() int                             { return 2 }
 func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
@@ -74,7 +159,13 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		return
 	}
 
-	str := fmt.Sprintf("%d. %s", index+1, i)
+	str := fmt.Sprintf("%d. %s", index+1, i.alias)
+	if d.marked[i.alias] {
+		str = markedStyle.Render("✓") + " " + str
+	}
+	if i.running {
+		str += " " + runningStyle.Render("● running")
+	}
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -83,97 +174,477 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		}
 	}
 
-	fmt.Fprint(w, fn(str))
+	fmt.Fprint(w, fn(str)+"\n"+itemStyle.Render("   "+i.details()))
 }
 
+// tab is what the list shows, the ashes or the running sessions
+type tab int
+
+const (
+	ashesTab tab = iota
+	sessionsTab
+)
+
+// inputAction is what the text input is asked for
+type inputAction int
+
+const (
+	noInput inputAction = iota
+	renameInput
+	freezeInput
+)
+
 type model struct {
-	list       list.Model
-	choice     string
-	quitting   bool
-	deleting   bool
+	list     list.Model
+	sessions list.Model
+	tab      tab
+	choice   string
+	quitting bool
+	//aliases waiting for the delete confirmation
+	deleting   []string
 	configPath string
+	width      int
+	//alias or session name of the selected item, to notice when the selection changes
+	selected string
+	//session whose active pane is in capture
+	captured string
+	capture  string
+	//sessions waiting for the kill confirmation
+	killing []string
+	//aliases marked for a batch action
+	marked map[string]bool
+	//session the input is asked for
+	target      string
+	inputAction inputAction
+	input       textinput.Model
+	//result of the last action on a session
+	status string
+	keys   *listKeyMap
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return m.selectionChanged()
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.list.SetWidth(msg.Width)
+// activeList is the list of the current tab
+func (m *model) activeList() *list.Model {
+	if m.tab == sessionsTab {
+		return &m.sessions
+	}
+	return &m.list
+}
+
+// selectionChanged captures the active pane of the selected ash or session when its session runs
+func (m *model) selectionChanged() tea.Cmd {
+	switch m.tab {
+	case sessionsTab:
+		s, ok := m.sessions.SelectedItem().(sessionItem)
+		if !ok || s.name == m.selected {
+			return nil
+		}
+		m.selected = s.name
+		// the active pane of the current session is this list
+		if s.current {
+			return nil
+		}
+		return capturePane(s.name)
+
+	default:
+		i, ok := m.list.SelectedItem().(item)
+		if !ok || i.alias == m.selected {
+			return nil
+		}
+		m.selected = i.alias
+		if !i.running {
+			return nil
+		}
+		return capturePane(i.ash.SessionName)
+	}
+}
+
+// listWidth leaves half of the terminal to the preview when it fits
+func (m model) listWidth() int {
+	if m.width/2 < minPreviewWidth {
+		return m.width
+	}
+	return m.width / 2
+}
+
+// refresh reloads the sessions and whether each ash runs after an action on sessions or ashes
+func (m *model) refresh(frozen string) tea.Cmd {
+	sessions := Backend.ListSessions()
+	items := m.list.Items()
+	for index, listItem := range items {
+		i, ok := listItem.(item)
+		if ok && i.err == nil && !i.base {
+			i.running = slices.Contains(sessions, i.ash.SessionName)
+			items[index] = i
+		}
+	}
+	if frozen != "" {
+		items = append(items, newItem(m.configPath, frozen, sessions))
+	}
+
+	cmds := []tea.Cmd{
+		setItems(&m.list, items),
+		setItems(&m.sessions, sessionItems(items)),
+	}
+	m.selected = ""
+	return tea.Batch(append(cmds, m.selectionChanged())...)
+}
+
+// setItems replaces the items of the list, removed items may leave the cursor past its end
+func setItems(l *list.Model, items []list.Item) tea.Cmd {
+	cmd := l.SetItems(items)
+	if visible := len(l.VisibleItems()); visible > 0 && l.Index() >= visible {
+		l.Select(visible - 1)
+	}
+	return cmd
+}
+
+// markedItems are the marked ashes in the order of the list or, when none is marked, the selected one
+func (m model) markedItems() []item {
+	items := []item{}
+	for _, listItem := range m.list.Items() {
+		i, ok := listItem.(item)
+		if ok && m.marked[i.alias] {
+			items = append(items, i)
+		}
+	}
+	if len(items) > 0 {
+		return items
+	}
+	i, ok := m.list.SelectedItem().(item)
+	if ok {
+		items = append(items, i)
+	}
+	return items
+}
+
+// deleteAshes deletes the ashes waiting for the confirmation
+func (m *model) deleteAshes() tea.Cmd {
+	deleted := []string{}
+	errs := []error{}
+	for _, alias := range m.deleting {
+		err := Delete(m.configPath, alias)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", alias, err))
+			continue
+		}
+		deleted = append(deleted, alias)
+		delete(m.marked, alias)
+	}
+	if len(errs) > 0 {
+		m.status = warningTextStyle.Render(errors.Join(errs...).Error())
+	} else {
+		m.status = detailStyle.Render("deleted " + strings.Join(deleted, ", "))
+	}
+
+	// the index of a filtered list is not the index of the item
+	items := slices.DeleteFunc(m.list.Items(), func(listItem list.Item) bool {
+		i, ok := listItem.(item)
+		return ok && slices.Contains(deleted, i.alias)
+	})
+	m.deleting = nil
+	m.list.SetItems(items)
+	return m.refresh("")
+}
+
+// reread reads the edited ash again so its row and preview show what the editor saved
+func (m *model) reread(alias string) tea.Cmd {
+	items := slices.Clone(m.list.Items())
+	aliases := []string{}
+	for _, listItem := range items {
+		if i, ok := listItem.(item); ok {
+			aliases = append(aliases, i.alias)
+		}
+	}
+	bases := extendedAliases(m.configPath, aliases)
+	sessions := Backend.ListSessions()
+	for index, listItem := range items {
+		if i, ok := listItem.(item); ok && i.alias == alias {
+			items[index] = newListItem(m.configPath, alias, bases, sessions)
+		}
+	}
+	return tea.Batch(setItems(&m.list, items), m.refresh(""))
+}
+
+// askInput starts asking for the new name of a session or the alias of the frozen ash
+func (m *model) askInput(action inputAction, target, value string) tea.Cmd {
+	m.inputAction = action
+	m.target = target
+	m.status = ""
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// updateInput handles the keys while the input is shown
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+
+	case "esc":
+		m.inputAction = noInput
+		m.input.Blur()
 		return m, nil
 
-	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "q", "ctrl+c":
-			m.quitting = true
-			return m, tea.Quit
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		action := m.inputAction
+		m.inputAction = noInput
+		m.input.Blur()
+		if value == "" {
+			return m, nil
+		}
+		if action == renameInput {
+			return m, renameSession(m.target, value)
+		}
+		return m, freezeSession(m.configPath, value, m.target)
+	}
 
-		case "enter":
-			if m.deleting {
-				break
-			}
-			i, ok := m.list.SelectedItem().(item)
-			if ok {
-				m.choice = strings.TrimSpace(string(i))
-				Choice = m.choice
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateConfirm handles the keys while a delete or a kill waits for confirmation
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.quit):
+		m.quitting = true
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.confirm):
+		if len(m.killing) > 0 {
+			sessionNames := m.killing
+			m.killing = nil
+			return m, killSessions(m.configPath, sessionNames)
+		}
+		return m, m.deleteAshes()
+
+	case key.Matches(msg, m.keys.cancel):
+		m.deleting = nil
+		m.killing = nil
+	}
+	return m, nil
+}
+
+// updateAshes handles the keys of the ashes tab
+func (m *model) updateAshes(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keys.markSelection):
+		i, ok := m.list.SelectedItem().(item)
+		if ok {
+			m.marked[i.alias] = !m.marked[i.alias]
+			if !m.marked[i.alias] {
+				delete(m.marked, i.alias)
 			}
-			return m, tea.Quit
+		}
+		return nil, true
 
-		case "e":
-			if m.deleting {
-				break
+	case key.Matches(msg, m.keys.openSelection):
+		marked := m.markedItems()
+		for _, i := range marked {
+			if i.base {
+				m.status = warningTextStyle.Render(i.alias + " is a base of other ashes, open an ash that extends it")
+				return nil, true
 			}
-			i, ok := m.list.SelectedItem().(item)
-			if ok {
-				filePath := fmt.Sprintf(
-					"%s/%s.yaml",
-					m.configPath,
-					strings.TrimSpace(string(i)),
-				)
-
-				editor := getEditor()
-				return m, tea.ExecProcess(
-					exec.Command(editor, filePath),
-					func(err error) tea.Msg {
-						return EditorError{err: err}
-					},
-				)
+		}
+		if len(marked) > 1 {
+			for _, i := range marked {
+				Choices = append(Choices, i.alias)
 			}
+			m.quitting = true
+			return tea.Quit, true
+		}
+		if len(marked) == 1 {
+			m.choice = marked[0].alias
+			Choice = m.choice
+		}
+		return tea.Quit, true
+
+	case key.Matches(msg, m.keys.editSelection):
+		i, ok := m.list.SelectedItem().(item)
+		if ok {
+			filePath := ashFilePath(m.configPath, i.alias)
+
+			editor := getEditor()
+			return tea.ExecProcess(
+				exec.Command(editor, filePath),
+				func(err error) tea.Msg {
+					return EditorError{alias: i.alias, err: err}
+				},
+			), true
+		}
 
-		case "d":
-			if m.deleting {
-				break
+	case key.Matches(msg, m.keys.deleteSelection):
+		for _, i := range m.markedItems() {
+			m.deleting = append(m.deleting, i.alias)
+		}
+		return nil, true
+
+	case key.Matches(msg, m.keys.killSelection):
+		current := Backend.CurrentSession()
+		for _, i := range m.markedItems() {
+			if !i.running {
+				continue
 			}
-			i, ok := m.list.SelectedItem().(item)
-			if ok {
-				m.deleting = true
-				m.choice = strings.TrimSpace(string(i))
+			if i.ash.SessionName == current {
+				m.status = warningTextStyle.Render("phoemux runs inside " + current + ", kill it from another session")
+				continue
 			}
+			m.killing = append(m.killing, i.ash.SessionName)
+		}
+		if len(m.killing) == 0 && m.status == "" {
+			m.status = detailStyle.Render("no running sessions to kill")
+		}
+		return nil, true
+	}
+	return nil, false
+}
 
-		case "y":
-			if !m.deleting {
-				break
-			}
-			m.deleting = false
-			idx := m.list.Index()
-			Delete(m.configPath, m.choice)
-			m.list.RemoveItem(idx)
-			m.choice = ""
-
-		case "n":
-			if !m.deleting {
-				break
+// updateSessions handles the keys of the sessions tab
+func (m *model) updateSessions(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s, ok := m.sessions.SelectedItem().(sessionItem)
+
+	switch {
+	case key.Matches(msg, m.keys.switchSession):
+		if ok {
+			SessionChoice = s.name
+		}
+		m.quitting = true
+		return tea.Quit, true
+
+	case key.Matches(msg, m.keys.killSession):
+		if !ok {
+			break
+		}
+		if s.current {
+			m.status = warningTextStyle.Render("phoemux runs inside " + s.name + ", kill it from another session")
+			return nil, true
+		}
+		m.killing = []string{s.name}
+		return nil, true
+
+	case key.Matches(msg, m.keys.renameSession):
+		if !ok {
+			break
+		}
+		return m.askInput(renameInput, s.name, s.name), true
+
+	case key.Matches(msg, m.keys.freezeSession):
+		if !ok {
+			break
+		}
+		return m.askInput(freezeInput, s.name, s.name), true
+	}
+	return nil, false
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.list.SetWidth(m.listWidth())
+		m.sessions.SetWidth(m.listWidth())
+		return m, nil
+
+	case captureMsg:
+		m.captured = msg.session
+		m.capture = msg.content
+		return m, nil
+
+	case EditorError:
+		if msg.err != nil {
+			m.status = warningTextStyle.Render(fmt.Sprintf("failed to edit %s: %s", msg.alias, msg.err))
+		}
+		return m, m.reread(msg.alias)
+
+	case sessionActionMsg:
+		if msg.err != nil {
+			m.status = warningTextStyle.Render(msg.err.Error())
+		} else {
+			m.status = detailStyle.Render(msg.status)
+		}
+		if output := strings.TrimSpace(msg.output); output != "" {
+			m.status += "\n  " + detailStyle.Render(strings.ReplaceAll(output, "\n", "\n  "))
+		}
+		return m, m.refresh(msg.frozen)
+
+	case tea.KeyMsg:
+		if m.inputAction != noInput {
+			return m.updateInput(msg)
+		}
+		if len(m.deleting) > 0 || len(m.killing) > 0 {
+			return m.updateConfirm(msg)
+		}
+		// while the filter is typed every key belongs to it
+		if m.activeList().FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keys.quit):
+			m.quitting = true
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.switchTab):
+			if m.tab == ashesTab {
+				m.tab = sessionsTab
+			} else {
+				m.tab = ashesTab
 			}
-			m.deleting = false
-			m.choice = ""
+			m.selected = ""
+			m.status = ""
+			return m, m.selectionChanged()
+		}
+
+		update := m.updateAshes
+		if m.tab == sessionsTab {
+			update = m.updateSessions
+		}
+		if cmd, handled := update(msg); handled {
+			return m, cmd
 		}
 	}
 
 	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	active := m.activeList()
+	*active, cmd = active.Update(msg)
+	return m, tea.Batch(cmd, m.selectionChanged())
+}
+
+// tabs renders the tab names with the current one highlighted
+func (m model) tabs() string {
+	names := []string{"Ashes", "Sessions"}
+	for index, name := range names {
+		if tab(index) == m.tab {
+			names[index] = activeTabStyle.Render(name)
+		} else {
+			names[index] = tabStyle.Render(name)
+		}
+	}
+	if m.tab == ashesTab && len(m.marked) > 0 {
+		names = append(names, markedStyle.Render(fmt.Sprintf("%d marked", len(m.marked))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, names...)
+}
+
+// confirm asks whether to run a destructive action on the targets
+func confirm(action, kind string, targets []string) string {
+	if len(targets) > 1 {
+		kind += "s"
+	}
+	return fmt.Sprintf(
+		"\n\n  %s %s %s %s %s\n",
+		boldTextStyle.Render("Do you wish to"),
+		warningTextStyle.Render(action),
+		boldTextStyle.Render("the "+kind),
+		keywordStyle.Render(strings.Join(targets, ", ")),
+		boldTextStyle.Render("? y/n"),
+	)
 }
 
 func (m model) View() string {
@@ -181,42 +652,83 @@ func (m model) View() string {
 		//TODO: maybe add a nice quitting message
 		return ""
 	}
-	if m.deleting {
+	if len(m.deleting) > 0 {
+		return confirm("delete", "item", m.deleting)
+	}
+	if len(m.killing) > 0 {
+		return confirm("kill", "session", m.killing)
+	}
+	if m.inputAction != noInput {
+		question := "New name for the session"
+		if m.inputAction == freezeInput {
+			question = "Alias of the ash created from"
+		}
 		return fmt.Sprintf(
-			"\n\n  %s %s %s %s %s\n",
-			boldTextStyle.Render("Do you wish to"),
-			warningTextStyle.Render("delete"),
-			boldTextStyle.Render("the item"),
-			keywordStyle.Render(m.choice),
-			boldTextStyle.Render("? y/n"),
+			"\n\n  %s %s\n\n  %s\n\n  %s\n",
+			boldTextStyle.Render(question),
+			keywordStyle.Render(m.target),
+			m.input.View(),
+			detailStyle.Render("enter to confirm, esc to cancel"),
 		)
 	}
 	if m.choice != "" {
 		return ""
 	}
-	return "\n" + m.list.View()
+
+	header := "\n" + m.tabs() + "\n"
+	if m.status != "" {
+		header += "  " + m.status + "\n"
+	}
+	active := m.activeList()
+	listWidth := m.listWidth()
+	if m.width == listWidth {
+		return header + active.View()
+	}
+	return header + lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		active.View(),
+		m.preview(m.width-listWidth, listHeight),
+	)
 }
 
-func newList(items []list.Item, configPath string) model {
-	listKeys := newListKeyMap()
+func newList(items []list.Item, configPath string, config Config) model {
+	keys := config.keys()
+	listKeys := newListKeyMap(keys)
+	marked := map[string]bool{}
 
-	l := list.New(items, itemDelegate{}, defaultWidth, listHeight)
+	l := list.New(items, itemDelegate{marked: marked}, defaultWidth, listHeight)
 	l.Title = "Ashes"
 	l.SetShowStatusBar(false)
-	l.SetFilteringEnabled(false)
+	l.SetFilteringEnabled(true)
 	l.Styles.Title = titleStyle
 	l.Styles.PaginationStyle = paginationStyle
 	l.Styles.HelpStyle = helpStyle
+	setListKeys(&l, keys)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listKeys.switchTab,
+		}
+	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			listKeys.openSelection,
+			listKeys.markSelection,
 			listKeys.editSelection,
 			listKeys.deleteSelection,
+			listKeys.killSelection,
 		}
 	}
 
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 64
+
 	return model{
 		list:       l,
+		sessions:   newSessionList(sessionItems(items), keys),
 		configPath: configPath,
+		input:      input,
+		marked:     marked,
+		keys:       listKeys,
 	}
 }
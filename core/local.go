@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+// LocalAshName is the name of the ash committed along with a project
+const LocalAshName = ".phoemux.yaml"
+
+var ErrNoLocalAsh = errors.New("no " + LocalAshName + " found")
+
+// FindLocalAsh returns the path of the closest project-local ash, looking in
+// dir and then in each of its parents
+func FindLocalAsh(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		filePath := filepath.Join(dir, LocalAshName)
+		info, err := os.Stat(filePath)
+		if err == nil && !info.IsDir() {
+			return filePath, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w in the current directory or its parents", ErrNoLocalAsh)
+		}
+		dir = parent
+	}
+}
+
+// readLocalAsh reads a project-local ash, its path is optional and
+// relative to the directory of the file so it can be shared
+func readLocalAsh(phoemuxConfigPath, filePath string) (tmux.Ash, error) {
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return tmux.Ash{}, fmt.Errorf("Failed to read ash: %w", err)
+	}
+
+	return parseAsh(phoemuxConfigPath, filePath, filepath.Dir(filePath), file)
+}
+
+// OpenLocal opens a project-local ash, the path of the file is kept in the history so last reopens it
+func OpenLocal(phoemuxConfigPath, filePath string) error {
+	ash, err := readLocalAsh(phoemuxConfigPath, filePath)
+	if err != nil {
+		return err
+	}
+
+	recordOpen(phoemuxConfigPath, filePath, ash.SessionName)
+
+	return openAsh(Backend, ash, runHooks)
+}
+
+// ValidateLocal checks a project-local ash
+func ValidateLocal(phoemuxConfigPath, filePath string) error {
+	_, err := readLocalAsh(phoemuxConfigPath, filePath)
+	return err
+}
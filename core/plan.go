@@ -0,0 +1,70 @@
+package core
+
+import (
+	"path/filepath"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+// Plan returns the commands that opening the ash would run, in order, without
+// running them. tmux commands come first and hooks are shown as the sh -c
+// invocation that runs them from the ash path
+func Plan(phoemuxConfigPath, alias string) ([]string, error) {
+	ash, err := readAsh(phoemuxConfigPath, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	return planAsh(ash)
+}
+
+// PlanLocal returns the plan of a project-local ash
+func PlanLocal(phoemuxConfigPath, filePath string) ([]string, error) {
+	ash, err := readLocalAsh(phoemuxConfigPath, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return planAsh(ash)
+}
+
+func planAsh(ash tmux.Ash) ([]string, error) {
+	recorder := &tmux.Recorder{}
+	if Backend.HasSession(ash.SessionName) {
+		recorder.Existing = []string{ash.SessionName}
+	}
+	plan := []string{}
+	flush := func() {
+		for _, args := range recorder.Commands {
+			plan = append(plan, tmux.CommandLine(args))
+		}
+		recorder.Commands = nil
+	}
+
+	err := openAsh(recorder, ash, func(ash tmux.Ash, commands []string) {
+		flush()
+		for _, command := range commands {
+			plan = append(plan, "sh -c "+tmux.Quote(command))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	flush()
+
+	return plan, nil
+}
+
+// PlanLast returns the plan of the nth most recently opened ash with the vars it was opened with
+func PlanLast(phoemuxConfigPath string, n int) ([]string, error) {
+	entry, err := lastEntry(phoemuxConfigPath, n)
+	if err != nil {
+		return nil, err
+	}
+	restoreVars(entry)
+
+	if filepath.IsAbs(entry.Alias) {
+		return PlanLocal(phoemuxConfigPath, entry.Alias)
+	}
+	return Plan(phoemuxConfigPath, entry.Alias)
+}
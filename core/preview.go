@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// the preview is only shown when the terminal leaves it at least this many columns
+const minPreviewWidth = 40
+
+var (
+	previewStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(defaultTheme.Detail)).
+			Padding(0, 1)
+	previewTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(defaultTheme.Selected))
+)
+
+// captureMsg carries the snapshot of the active pane of a running session
+type captureMsg struct {
+	session string
+	content string
+}
+
+func capturePane(sessionName string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := Backend.CapturePane(sessionName)
+		if err != nil {
+			content = err.Error()
+		}
+		return captureMsg{session: sessionName, content: content}
+	}
+}
+
+// describeAsh renders the windows and terminals of the ash
+func describeAsh(ash tmux.Ash) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", previewTitleStyle.Render(ash.SessionName))
+	fmt.Fprintf(&b, "%s\n", detailStyle.Render(ash.Path))
+	if ash.Extends != "" {
+		fmt.Fprintf(&b, "%s\n", detailStyle.Render("extends "+ash.Extends))
+	}
+
+	for _, window := range ash.Windows {
+		name := window.Name
+		if window.Name == ash.DefaultWindow {
+			name += " (default)"
+		}
+		options := []string{}
+		if window.Split != "" {
+			options = append(options, "split "+window.Split)
+		}
+		if window.Layout != "" {
+			options = append(options, "layout "+window.Layout)
+		}
+		if window.Path != "" {
+			options = append(options, tmux.WindowPath(ash, window))
+		}
+		fmt.Fprintf(&b, "\n%s %s\n", boldTextStyle.Render(name), detailStyle.Render(strings.Join(options, " · ")))
+
+		for j, terminal := range window.Terminals {
+			command := terminal.Command
+			if command == "" {
+				command = detailStyle.Render("shell")
+			}
+			fmt.Fprintf(&b, "  %d. %s", j+1, command)
+			if terminal.Path != "" {
+				fmt.Fprintf(&b, " %s", detailStyle.Render("in "+tmux.TerminalPath(ash, window, terminal)))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// preview renders the selected ash or session and, when its session runs, what its active pane shows
+func (m model) preview(width, height int) string {
+	// the border takes two rows
+	inner := height - 2
+
+	var content, capture string
+	switch m.tab {
+	case ashesTab:
+		i, ok := m.list.SelectedItem().(item)
+		if !ok {
+			return ""
+		}
+		if i.running && m.captured == i.ash.SessionName {
+			capture = "\n" + previewTitleStyle.Render("active pane") + "\n" + lastLines(m.capture, inner/2)
+		}
+		if i.err != nil {
+			content = warningTextStyle.Render(i.err.Error())
+		} else {
+			content = describeAsh(i.ash)
+		}
+
+	case sessionsTab:
+		s, ok := m.sessions.SelectedItem().(sessionItem)
+		if !ok {
+			return ""
+		}
+		content = previewTitleStyle.Render(s.name) + "\n" + s.details()
+		if m.captured == s.name {
+			capture = "\n\n" + previewTitleStyle.Render("active pane") + "\n" + lastLines(m.capture, inner-3)
+		}
+	}
+	content = firstLines(content, inner-strings.Count(capture, "\n")) + capture
+
+	// the border and padding take four columns
+	return previewStyle.
+		Width(width - 4).
+		MaxWidth(width).
+		Render(content)
+}
+
+func firstLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if n >= 0 && len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lastLines keeps the last lines that fit, tmux pads captures with empty lines
+func lastLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n "), "\n")
+	if n >= 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// replaceValue sets the value at the yaml path keeping the rest of the file, comments included
+func replaceValue(content []byte, yamlPath, value string) ([]byte, error) {
+	file, err := parser.ParseBytes(content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	path, err := yaml.PathString(yamlPath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := path.FilterFile(file)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := parser.ParseBytes([]byte(fmt.Sprintf("%q", value)), 0)
+	if err != nil {
+		return nil, err
+	}
+	node := replacement.Docs[0].Body
+	// the comment after the value belongs to its node
+	if comment := current.GetComment(); comment != nil {
+		node.SetComment(comment)
+	}
+	err = path.ReplaceWithNode(file, node)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(file.String(), "\n") + "\n"), nil
+}
+
+// copyAsh writes the ash of src as dst, the session name is changed to dst
+// when it was src and the returned bool tells if that happened
+func copyAsh(phoemuxConfigPath, src, dst string) (bool, error) {
+	if src == "" || dst == "" {
+		return false, fmt.Errorf("expected the alias of an ash and a new alias")
+	}
+	if strings.ContainsAny(dst, "/\\") {
+		return false, fmt.Errorf("alias %s can not contain a path separator", dst)
+	}
+	if err := reservedAlias(dst); err != nil {
+		return false, err
+	}
+	if !ashExist(phoemuxConfigPath, src) {
+		return false, fmt.Errorf("ash %s does not exist", src)
+	}
+	dstPath := ashFilePath(phoemuxConfigPath, dst)
+	if fileExist(dstPath) {
+		return false, fmt.Errorf("ash for %s already exist", dst)
+	}
+
+	content, err := os.ReadFile(ashFilePath(phoemuxConfigPath, src))
+	if err != nil {
+		return false, fmt.Errorf("Failed to read ash: %w", err)
+	}
+
+	var ash tmux.Ash
+	err = yaml.Unmarshal(content, &ash)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse ash %s: %s", src, yaml.FormatError(err, false, true))
+	}
+
+	renamed := ash.SessionName == src
+	if renamed {
+		content, err = replaceValue(content, "$.sessionName", dst)
+		if err != nil {
+			return false, fmt.Errorf("failed to update the session name: %w", err)
+		}
+	}
+
+	err = os.WriteFile(dstPath, content, 0666)
+	if err != nil {
+		return false, fmt.Errorf("Failed to write ash: %w", err)
+	}
+	return renamed, nil
+}
+
+// Copy duplicates the ash of src as dst
+func Copy(phoemuxConfigPath, src, dst string) error {
+	_, err := copyAsh(phoemuxConfigPath, src, dst)
+	return err
+}
+
+// Rename moves the ash to the new alias updating the session name when it was the alias,
+// the ashes extending it and the history used by last. When renameSession is set a running
+// session of the ash is renamed too
+func Rename(phoemuxConfigPath, oldAlias, newAlias string, renameSession bool) error {
+	renamed, err := copyAsh(phoemuxConfigPath, oldAlias, newAlias)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(ashFilePath(phoemuxConfigPath, oldAlias))
+	if err != nil {
+		return fmt.Errorf("failed to remove ash %s: %w", oldAlias, err)
+	}
+
+	ashes, err := GetSimpleList(phoemuxConfigPath)
+	if err != nil {
+		return err
+	}
+	for _, alias := range ashes {
+		filePath := ashFilePath(phoemuxConfigPath, alias)
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		var ash tmux.Ash
+		if yaml.Unmarshal(content, &ash) != nil || ash.Extends != oldAlias {
+			continue
+		}
+		content, err = replaceValue(content, "$.extends", newAlias)
+		if err == nil {
+			err = os.WriteFile(filePath, content, 0666)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update extends of ash %s: %w", alias, err)
+		}
+	}
+
+	sessionName := ""
+	if renamed {
+		sessionName = newAlias
+	}
+	err = renameInHistory(phoemuxConfigPath, oldAlias, newAlias, sessionName)
+	if err != nil {
+		return err
+	}
+
+	if renameSession && renamed && Backend.HasSession(oldAlias) {
+		return Backend.RenameSession(oldAlias, newAlias)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+const schemaFileName = "ash.schema.json"
+
+// descriptions of the ash fields, keyed by struct and yaml name
+var schemaDescriptions = map[string]string{
+	"Ash":               "workspace recreated by phoemux as a tmux session",
+	"Ash.extends":       "alias of an ash to inherit from, windows with the same name override the inherited ones and the rest are appended",
+	"Ash.path":          "directory where the session starts, required except in a .phoemux.yaml where it defaults to the directory of the file",
+	"Ash.sessionName":   "name of the tmux session",
+	"Ash.vars":          "values of ${name} and {{ .name }} in the paths, the session name and the commands, they override the environment and --set overrides them",
+	"Ash.template":      "expand {{ .name }} in the paths, the session name and the commands as go templates, {{ is kept as is otherwise",
+	"Ash.env":           "environment of the session, windows and terminals inherit and may override it",
+	"Ash.hooks":         "commands run on the host, outside of tmux, from the ash path",
+	"Ash.defaultWindow": "window selected after the session is created, defaults to the first one",
+	"Ash.windows":       "windows of the session, in order",
+	"Hooks.onCreate":    "run after the session is created",
+	"Hooks.onAttach":    "run every time an already existing session is attached",
+	"Hooks.onKill":      "run before the session is killed",
+	"Window.name":       "name of the window",
+	"Window.split":      "direction used to create the panes, horizontal puts them side by side",
+	"Window.layout":     "tmux layout applied after the panes are created",
+	"Window.path":       "directory of the window, relative paths are resolved against the ash path",
+	"Window.env":        "environment of the window panes",
+	"Window.terminals":  "panes of the window, every terminal after the first one is a split",
+	"Terminal.command":  "command sent to the pane",
+	"Terminal.path":     "directory of the pane, relative paths are resolved against the ash path",
+	"Terminal.size":     "size of the pane in cells or a percentage like 30%",
+	"Terminal.env":      "environment of the pane",
+}
+
+// schemaOverrides restricts the values of fields beyond their go type
+var schemaOverrides = map[string]map[string]any{
+	"Window.split": {
+		"enum": splitValues,
+	},
+	"Window.layout": {
+		"anyOf": []any{
+			map[string]any{
+				"enum":        layoutValues,
+				"description": "one of the layouts built into tmux",
+			},
+			map[string]any{
+				"pattern":     customLayoutRegex.String(),
+				"description": "custom layout string as printed by tmux list-windows",
+			},
+		},
+	},
+	"Terminal.size": {
+		"pattern": sizeRegex.String(),
+	},
+}
+
+// required lists the fields that the validation needs, keyed by struct name. The ash requires
+// none since project-local ashes may omit the path and extended ashes everything but their
+// windows, the validation checks them once the ash is merged
+var schemaRequired = map[string][]string{
+	"Window": {"name", "terminals"},
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := t.Name() + "." + name
+		property := typeSchema(field.Type)
+		if description, ok := schemaDescriptions[key]; ok {
+			property["description"] = description
+		}
+		for k, v := range schemaOverrides[key] {
+			property[k] = v
+		}
+		if _, ok := property["anyOf"]; ok {
+			delete(property, "type")
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if required := schemaRequired[t.Name()]; len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Schema returns the JSON Schema of the ash files
+func Schema() ([]byte, error) {
+	schema := structSchema(reflect.TypeOf(tmux.Ash{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "phoemux ash"
+	schema["description"] = schemaDescriptions["Ash"]
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func SchemaPath(phoemuxConfigPath string) string {
+	return fmt.Sprintf(
+		"%s/%s",
+		phoemuxConfigPath,
+		schemaFileName,
+	)
+}
+
+// WriteSchema stores the schema in the config directory so editors can use it
+func WriteSchema(phoemuxConfigPath string) error {
+	schema, err := Schema()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+
+	err = os.WriteFile(SchemaPath(phoemuxConfigPath), schema, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
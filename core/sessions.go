@@ -0,0 +1,158 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionItem is a running tmux session, it may not have been created by phoemux
+type sessionItem struct {
+	name string
+	//alias of the ash that creates the session, if any
+	alias string
+	//phoemux runs inside this session
+	current bool
+}
+
+func (s sessionItem) FilterValue() string {
+	return s.name + " " + s.alias
+}
+
+func (s sessionItem) details() string {
+	if s.alias == "" {
+		return detailStyle.Render("not created by phoemux")
+	}
+	return detailStyle.Render("from ash " + s.alias)
+}
+
+// sessionItems lists the running sessions, ashes are the items of the
+// ashes tab which are used to know which ash created each session
+func sessionItems(ashes []list.Item) []list.Item {
+	aliases := map[string]string{}
+	for _, listItem := range ashes {
+		i, ok := listItem.(item)
+		if ok && i.err == nil && !i.base {
+			aliases[i.ash.SessionName] = i.alias
+		}
+	}
+
+	current := Backend.CurrentSession()
+	items := []list.Item{}
+	for _, name := range Backend.ListSessions() {
+		items = append(items, sessionItem{
+			name:    name,
+			alias:   aliases[name],
+			current: name == current,
+		})
+	}
+	return items
+}
+
+type sessionDelegate struct{}
+
+func (d sessionDelegate) Height() int                             { return 2 }
+func (d sessionDelegate) Spacing() int                            { return 0 }
+func (d sessionDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d sessionDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	s, ok := listItem.(sessionItem)
+	if !ok {
+		return
+	}
+
+	str := fmt.Sprintf("%d. %s", index+1, s.name)
+	if s.current {
+		str += " " + runningStyle.Render("● current")
+	}
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			return selectedItemStyle.Render("->  " + strings.Join(s, " "))
+		}
+	}
+
+	fmt.Fprint(w, fn(str)+"\n"+itemStyle.Render("   "+s.details()))
+}
+
+func newSessionList(items []list.Item, keys map[string][]string) list.Model {
+	listKeys := newListKeyMap(keys)
+
+	l := list.New(items, sessionDelegate{}, defaultWidth, listHeight)
+	l.Title = "Sessions"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = titleStyle
+	l.Styles.PaginationStyle = paginationStyle
+	l.Styles.HelpStyle = helpStyle
+	setListKeys(&l, keys)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listKeys.switchTab,
+		}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			listKeys.switchSession,
+			listKeys.killSession,
+			listKeys.renameSession,
+			listKeys.freezeSession,
+		}
+	}
+	return l
+}
+
+// sessionActionMsg reports the result of an action on a session
+type sessionActionMsg struct {
+	status string
+	err    error
+	//alias of the ash created by freeze
+	frozen string
+	//what the hooks run by the action printed
+	output string
+}
+
+// killSessions kills the sessions, the output of their onKill hooks is
+// captured since the list still owns the terminal
+func killSessions(phoemuxConfigPath string, sessionNames []string) tea.Cmd {
+	return func() tea.Msg {
+		var output strings.Builder
+		hooks := func(ash tmux.Ash, commands []string) {
+			output.WriteString(captureHooks(ash, commands))
+		}
+
+		errs := []error{}
+		for _, sessionName := range sessionNames {
+			err := killWithHooks(phoemuxConfigPath, sessionName, hooks)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sessionName, err))
+			}
+		}
+		return sessionActionMsg{
+			status: "killed " + strings.Join(sessionNames, ", "),
+			err:    errors.Join(errs...),
+			output: output.String(),
+		}
+	}
+}
+
+func renameSession(sessionName, newName string) tea.Cmd {
+	return func() tea.Msg {
+		err := Backend.RenameSession(sessionName, newName)
+		return sessionActionMsg{status: fmt.Sprintf("renamed %s to %s", sessionName, newName), err: err}
+	}
+}
+
+func freezeSession(phoemuxConfigPath, alias, sessionName string) tea.Cmd {
+	return func() tea.Msg {
+		err := Freeze(phoemuxConfigPath, alias, sessionName)
+		return sessionActionMsg{status: fmt.Sprintf("froze %s into ash %s", sessionName, alias), err: err, frozen: alias}
+	}
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	DefaultTemplate  = "default"
+	templatesDirName = "templates"
+)
+
+// builtinTemplate is used when the files of a project match one of its markers
+type builtinTemplate struct {
+	markers []string
+	body    string
+}
+
+var builtinTemplates = map[string]builtinTemplate{
+	DefaultTemplate: {
+		body: `defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: echo "do something here"
+- name: servers
+  split: horizontal
+  terminals:
+  - command: ls
+  - command: echo "another pane"
+`,
+	},
+	"go": {
+		markers: []string{"go.mod"},
+		body: `defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: ${EDITOR:-vi} .
+- name: tests
+  terminals:
+  - command: go test ./...
+- name: run
+  split: horizontal
+  terminals:
+  - command: go run .
+  - command: git status
+`,
+	},
+	"node": {
+		markers: []string{"package.json"},
+		body: `defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: ${EDITOR:-vi} .
+- name: dev
+  split: horizontal
+  terminals:
+  - command: npm install && npm run dev
+  - command: npm test
+`,
+	},
+	"python": {
+		markers: []string{"pyproject.toml", "requirements.txt", "setup.py"},
+		body: `defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: ${EDITOR:-vi} .
+- name: shell
+  split: horizontal
+  terminals:
+  - command: python3
+  - command: python3 -m pytest
+`,
+	},
+	"rails": {
+		markers: []string{"bin/rails", "config/application.rb"},
+		body: `defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: ${EDITOR:-vi} .
+- name: server
+  split: horizontal
+  terminals:
+  - command: bin/rails server
+  - command: tail -f log/development.log
+- name: console
+  terminals:
+  - command: bin/rails console
+`,
+	},
+}
+
+// rails projects also have a package.json so they are detected first
+var detectOrder = []string{"rails", "node", "go", "python"}
+
+func TemplatesPath(phoemuxConfigPath string) string {
+	return filepath.Join(phoemuxConfigPath, templatesDirName)
+}
+
+// ListTemplates returns the templates of the config directory and the built-in ones
+func ListTemplates(phoemuxConfigPath string) []string {
+	templates := []string{}
+	for name := range builtinTemplates {
+		templates = append(templates, name)
+	}
+
+	files, err := os.ReadDir(TemplatesPath(phoemuxConfigPath))
+	if err == nil {
+		for _, file := range files {
+			name, found := strings.CutSuffix(file.Name(), ".yaml")
+			if found && !slices.Contains(templates, name) {
+				templates = append(templates, name)
+			}
+		}
+	}
+
+	slices.Sort(templates)
+	return templates
+}
+
+// DetectTemplate returns the built-in template matching the files in pwd
+func DetectTemplate(pwd string) string {
+	for _, name := range detectOrder {
+		for _, marker := range builtinTemplates[name].markers {
+			if fileExist(filepath.Join(pwd, marker)) {
+				return name
+			}
+		}
+	}
+	return DefaultTemplate
+}
+
+// templateBody returns the template, the ones in the config directory take
+// precedence over the built-in ones so they can be customized
+func templateBody(phoemuxConfigPath, name string) (string, error) {
+	filePath := filepath.Join(TemplatesPath(phoemuxConfigPath), name+".yaml")
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		template, ok := builtinTemplates[name]
+		if !ok {
+			return "", fmt.Errorf(
+				"template %s does not exist, available templates: %s",
+				name,
+				strings.Join(ListTemplates(phoemuxConfigPath), ", "),
+			)
+		}
+		return template.body, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	var ash tmux.Ash
+	err = yaml.UnmarshalWithOptions(content, &ash, yaml.Strict())
+	if err != nil {
+		return "", fmt.Errorf("%s %s", filePath, yaml.FormatError(err, false, true))
+	}
+	if ash.Path != "" || ash.SessionName != "" {
+		return "", fmt.Errorf("%s: templates can not set path or sessionName, they are set by create", filePath)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,377 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+var (
+	splitValues  = []string{"horizontal", "vertical"}
+	layoutValues = []string{
+		"even-horizontal",
+		"even-vertical",
+		"main-horizontal",
+		"main-vertical",
+		"tiled",
+	}
+	// custom layouts start with a checksum and the size of the window, e.g. 5e4f,80x24,0,0
+	customLayoutRegex = regexp.MustCompile(`^[0-9a-f]{4},\d+x\d+,\d+,\d+`)
+	sizeRegex         = regexp.MustCompile(`^\d+%?$`)
+	windowPathRegex   = regexp.MustCompile(`^\$\.windows\[(\d+)\]`)
+)
+
+// ashSource is a file that defines part of an ash, the ash itself or one of the ashes it extends
+type ashSource struct {
+	filePath string
+	file     *ast.File
+	//names of the windows of the file, to find a merged window in it
+	windows []string
+}
+
+func newAshSource(filePath string, content []byte, ash tmux.Ash) ashSource {
+	file, _ := parser.ParseBytes(content, 0)
+	windows := []string{}
+	for _, window := range ash.Windows {
+		windows = append(windows, window.Name)
+	}
+	return ashSource{filePath: filePath, file: file, windows: windows}
+}
+
+// position returns where the value of the yaml path starts in the file, 0 when it is not there
+func (s ashSource) position(yamlPath string) (int, int) {
+	if s.file == nil || yamlPath == "" {
+		return 0, 0
+	}
+	path, err := yaml.PathString(yamlPath)
+	if err != nil {
+		return 0, 0
+	}
+	node, err := path.FilterFile(s.file)
+	if err != nil || node == nil {
+		return 0, 0
+	}
+	position := node.GetToken().Position
+	return position.Line, position.Column
+}
+
+// ashValidator collects the problems of an ash along with their position in the file
+type ashValidator struct {
+	//sources holds the ash first and then the ashes it extends, a problem is reported
+	//in the first one that defines the value
+	sources []ashSource
+	//windows are the names of the windows of the merged ash
+	windows []string
+	//partial ashes are only extended by other ashes so they may leave out
+	//the path, the session name and the windows, and their paths are not checked
+	partial bool
+	//skipDirs does not check that the paths exist
+	skipDirs bool
+	errs     []error
+}
+
+func (v *ashValidator) report(yamlPath, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	filePath, line, column := v.position(yamlPath)
+	if line == 0 {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", filePath, message))
+		return
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s:%d:%d: %s", filePath, line, column, message))
+}
+
+// position returns the file and where the value of the yaml path starts, falling back to
+// its closest parent, the line is 0 when none of them is in the files
+func (v *ashValidator) position(yamlPath string) (string, int, int) {
+	for yamlPath != "$" {
+		for _, source := range v.sources {
+			line, column := source.position(v.sourcePath(source, yamlPath))
+			if line != 0 {
+				return source.filePath, line, column
+			}
+		}
+		yamlPath = yamlPath[:strings.LastIndexAny(yamlPath, ".[")]
+	}
+	return v.sources[0].filePath, 0, 0
+}
+
+// sourcePath translates the yaml path of the merged ash to the source, the nth window
+// with a name is the nth window with that name in the source, empty when it has none
+func (v *ashValidator) sourcePath(source ashSource, yamlPath string) string {
+	match := windowPathRegex.FindStringSubmatch(yamlPath)
+	if match == nil {
+		return yamlPath
+	}
+	index, _ := strconv.Atoi(match[1])
+	if index >= len(v.windows) {
+		return ""
+	}
+	name := v.windows[index]
+	nth := 0
+	for _, other := range v.windows[:index] {
+		if other == name {
+			nth++
+		}
+	}
+	for i, other := range source.windows {
+		if other != name {
+			continue
+		}
+		if nth == 0 {
+			return fmt.Sprintf("$.windows[%d]", i) + yamlPath[len(match[0]):]
+		}
+		nth--
+	}
+	return ""
+}
+
+func (v *ashValidator) checkDir(yamlPath, dir string) {
+	if v.partial || v.skipDirs {
+		return
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		v.report(yamlPath, "path %s does not exist", dir)
+		return
+	}
+	if !info.IsDir() {
+		v.report(yamlPath, "path %s is not a directory", dir)
+	}
+}
+
+func (v *ashValidator) checkEnv(yamlPath string, env map[string]string) {
+	for key := range env {
+		if key == "" || strings.Contains(key, "=") {
+			v.report(yamlPath, "invalid environment variable name %q", key)
+		}
+	}
+}
+
+func (v *ashValidator) validate(ash tmux.Ash) {
+	for _, window := range ash.Windows {
+		v.windows = append(v.windows, window.Name)
+	}
+
+	if ash.Path == "" {
+		if !v.partial {
+			v.report("$", "path is required")
+		}
+	} else {
+		v.checkDir("$.path", ash.Path)
+	}
+
+	if ash.SessionName == "" {
+		if !v.partial {
+			v.report("$", "sessionName is required")
+		}
+	} else if strings.ContainsAny(ash.SessionName, ":.") {
+		v.report("$.sessionName", "sessionName can not contain : or .")
+	}
+	v.checkEnv("$.env", ash.Env)
+
+	if len(ash.Windows) == 0 {
+		if !v.partial {
+			v.report("$", "at least one window is required")
+		}
+		return
+	}
+
+	names := []string{}
+	for i, window := range ash.Windows {
+		windowPath := fmt.Sprintf("$.windows[%d]", i)
+
+		if window.Name == "" {
+			v.report(windowPath, "window name is required")
+		} else if slices.Contains(names, window.Name) {
+			v.report(windowPath+".name", "window %s is defined more than once", window.Name)
+		} else if strings.ContainsAny(window.Name, ":.") {
+			v.report(windowPath+".name", "window name can not contain : or .")
+		}
+		names = append(names, window.Name)
+
+		if window.Split != "" && !slices.Contains(splitValues, window.Split) {
+			v.report(
+				windowPath+".split",
+				"split must be one of %s, got %q",
+				strings.Join(splitValues, ", "),
+				window.Split,
+			)
+		}
+
+		if window.Layout != "" &&
+			!slices.Contains(layoutValues, window.Layout) &&
+			!customLayoutRegex.MatchString(window.Layout) {
+			v.report(
+				windowPath+".layout",
+				"layout must be one of %s or a custom layout string, got %q",
+				strings.Join(layoutValues, ", "),
+				window.Layout,
+			)
+		}
+
+		if window.Path != "" && ash.Path != "" {
+			v.checkDir(windowPath+".path", tmux.WindowPath(ash, window))
+		}
+		v.checkEnv(windowPath+".env", window.Env)
+
+		if len(window.Terminals) == 0 {
+			v.report(windowPath+".terminals", "window %s needs at least one terminal", window.Name)
+		}
+
+		for j, terminal := range window.Terminals {
+			terminalPath := fmt.Sprintf("%s.terminals[%d]", windowPath, j)
+			if terminal.Size != "" && !sizeRegex.MatchString(terminal.Size) {
+				v.report(terminalPath+".size", "size must be a number of cells or a percentage, got %q", terminal.Size)
+			}
+			if terminal.Path != "" && ash.Path != "" {
+				v.checkDir(terminalPath+".path", tmux.TerminalPath(ash, window, terminal))
+			}
+			v.checkEnv(terminalPath+".env", terminal.Env)
+		}
+	}
+
+	if ash.DefaultWindow != "" && !slices.Contains(names, ash.DefaultWindow) {
+		v.report("$.defaultWindow", "defaultWindow %s is not one of the windows", ash.DefaultWindow)
+	}
+}
+
+// decodeAsh decodes the ash rejecting unknown keys and merges it with the ashes it extends.
+// The sources are returned to locate problems, the ash comes first followed by the ashes it extends
+func decodeAsh(phoemuxConfigPath, filePath string, content []byte) (tmux.Ash, []ashSource, error) {
+	var ash tmux.Ash
+
+	err := yaml.UnmarshalWithOptions(content, &ash, yaml.Strict())
+	if err != nil {
+		return ash, nil, fmt.Errorf("%s %s", filePath, yaml.FormatError(err, false, true))
+	}
+
+	sources := []ashSource{newAshSource(filePath, content, ash)}
+	if ash.Extends != "" {
+		var bases []ashSource
+		ash, bases, err = extendAsh(phoemuxConfigPath, ash, []string{filePath})
+		if err != nil {
+			return ash, nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		sources = append(sources, bases...)
+	}
+
+	return ash, sources, nil
+}
+
+// parseAsh decodes the ash, expands its variables and checks that tmux will be able to build it,
+// when dir is set a missing or relative path of the ash is resolved against it
+func parseAsh(phoemuxConfigPath, filePath, dir string, content []byte) (tmux.Ash, error) {
+	return decodeAndValidate(phoemuxConfigPath, filePath, dir, content, Vars, false)
+}
+
+// decodeAndValidate does the work of parseAsh with the vars given by set, skipDirs leaves
+// out the check of the paths for the ashes that are looked up without being opened
+func decodeAndValidate(phoemuxConfigPath, filePath, dir string, content []byte, set map[string]string, skipDirs bool) (tmux.Ash, error) {
+	ash, sources, err := decodeAsh(phoemuxConfigPath, filePath, content)
+	if err != nil {
+		return ash, err
+	}
+
+	ash, err = expandAsh(ash, set)
+	if err != nil {
+		return ash, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	if dir != "" {
+		if ash.Path == "" {
+			ash.Path = dir
+		} else if !filepath.IsAbs(ash.Path) {
+			ash.Path = filepath.Join(dir, ash.Path)
+		}
+	}
+
+	validator := ashValidator{
+		sources:  sources,
+		skipDirs: skipDirs,
+	}
+	validator.validate(ash)
+
+	if len(validator.errs) > 0 {
+		return ash, errors.Join(validator.errs...)
+	}
+
+	if ash.DefaultWindow == "" {
+		ash.DefaultWindow = ash.Windows[0].Name
+	}
+
+	return ash, nil
+}
+
+// validateBase checks an ash that other ashes extend, it may be partial and its
+// variables and paths depend on the ash extending it so they are not checked
+func validateBase(phoemuxConfigPath, alias string) error {
+	_, err := readBase(phoemuxConfigPath, alias)
+	return err
+}
+
+// partialAsh tells if the ash leaves out the path, the session name or the windows, which only
+// an ash that is extended may do, a complete ash is checked as any other even when it is extended
+func partialAsh(phoemuxConfigPath, alias string) bool {
+	ash, _ := readBase(phoemuxConfigPath, alias)
+	return ash.Path == "" || ash.SessionName == "" || len(ash.Windows) == 0
+}
+
+// readBase decodes and checks an ash that other ashes extend, see validateBase
+func readBase(phoemuxConfigPath, alias string) (tmux.Ash, error) {
+	filePath := ashFilePath(phoemuxConfigPath, alias)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return tmux.Ash{}, fmt.Errorf("Failed to read ash: %w", err)
+	}
+
+	ash, sources, err := decodeAsh(phoemuxConfigPath, filePath, content)
+	if err != nil {
+		return ash, err
+	}
+
+	validator := ashValidator{
+		sources: sources,
+		partial: true,
+	}
+	validator.validate(ash)
+	return ash, errors.Join(validator.errs...)
+}
+
+// Validate checks the ash of the alias
+func Validate(phoemuxConfigPath, alias string) error {
+	_, err := readAsh(phoemuxConfigPath, alias)
+	return err
+}
+
+// ValidateAll checks every ash and returns the problems of all of them
+func ValidateAll(phoemuxConfigPath string) error {
+	ashes, err := GetSimpleList(phoemuxConfigPath)
+	if err != nil {
+		return err
+	}
+
+	bases := extendedAliases(phoemuxConfigPath, ashes)
+	errs := []error{}
+	for _, alias := range ashes {
+		if slices.Contains(bases, alias) && partialAsh(phoemuxConfigPath, alias) {
+			err = validateBase(phoemuxConfigPath, alias)
+		} else {
+			err = Validate(phoemuxConfigPath, alias)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
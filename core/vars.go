@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+var (
+	// Vars are set from the command line with --set and override the vars of the ash
+	Vars = map[string]string{}
+
+	varRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// ParseVar splits a key=value assignment given to --set
+func ParseVar(assignment string) (string, string, error) {
+	key, value, found := strings.Cut(assignment, "=")
+	if !found || key == "" {
+		return "", "", fmt.Errorf("invalid variable %q, expected key=value", assignment)
+	}
+	return key, value, nil
+}
+
+// ashVars returns the values available to the ash, the environment is overridden by the
+// env of the ash, window and terminal in scope, those by the vars of the ash and those by set
+func ashVars(ash tmux.Ash, set map[string]string, envs ...map[string]string) map[string]string {
+	vars := map[string]string{}
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		vars[key] = value
+	}
+	for _, env := range envs {
+		maps.Copy(vars, env)
+	}
+	maps.Copy(vars, ash.Vars)
+	maps.Copy(vars, set)
+	return vars
+}
+
+// expand replaces ${name} with its value, unknown names are left for the shell
+// of the pane, and then runs the value as a go template like {{ .name }} when the
+// ash enables templates
+func expand(value string, vars map[string]string, templated bool) (string, error) {
+	value = varRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if v, ok := vars[match[2:len(match)-1]]; ok {
+			return v
+		}
+		return match
+	})
+
+	if !templated || !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %q: %w", value, err)
+	}
+	var expanded strings.Builder
+	err = tmpl.Execute(&expanded, vars)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand %q: %w", value, err)
+	}
+	return expanded.String(), nil
+}
+
+// expandAsh expands the variables used in the paths, the session name and the commands,
+// set holds the values given with --set
+func expandAsh(ash tmux.Ash, set map[string]string) (tmux.Ash, error) {
+	var err error
+	expandAll := func(vars map[string]string, fields ...*string) {
+		for _, field := range fields {
+			if err != nil {
+				return
+			}
+			*field, err = expand(*field, vars, ash.Template)
+		}
+	}
+
+	expandAll(ashVars(ash, set, ash.Env), &ash.Path, &ash.SessionName)
+	for i := range ash.Windows {
+		window := &ash.Windows[i]
+		expandAll(ashVars(ash, set, ash.Env, window.Env), &window.Path)
+		for j := range window.Terminals {
+			terminal := &window.Terminals[j]
+			expandAll(ashVars(ash, set, ash.Env, window.Env, terminal.Env), &terminal.Path, &terminal.Command)
+		}
+	}
+	return ash, err
+}
@@ -1,25 +1,39 @@
 package tests
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/jhonnyV-V/phoemux/core"
-	"github.com/jhonnyV-V/phoemux/tmux"
 )
 
 func TestKill(t *testing.T) {
 	phoemuxConfigPath := core.GetConfigPath()
 	target := "vc"
+	fake := setup(t, target, fmt.Sprintf(`path: "%s"
+sessionName: "vc"
+defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+`, t.TempDir()))
 
-	core.Open(phoemuxConfigPath, target)
+	err := core.Open(phoemuxConfigPath, target)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %s\n", target, err)
+	}
 
-	if !tmux.HasSession(target) {
+	if !fake.HasSession(target) {
 		t.Fatalf("Failed to created the session\n")
 	}
 
-	tmux.Kill(target)
+	err = core.Kill(phoemuxConfigPath, target)
+	if err != nil {
+		t.Fatalf("Failed to kill target %s: %s\n", target, err)
+	}
 
-	if tmux.HasSession(target) {
+	if fake.HasSession(target) {
 		t.Fatalf("Failed to kill target: %s\n", target)
 	}
 }
@@ -1,22 +1,30 @@
 package tests
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/jhonnyV-V/phoemux/core"
-	"github.com/jhonnyV-V/phoemux/tmux"
 )
 
 func TestRoot(t *testing.T) {
 	phoemuxConfigPath := core.GetConfigPath()
 	target := "phoemux"
-	shouldKill := tmux.GetCurrentSessionName() != target
-	expectedActive := ""
-	if shouldKill {
-		expectedActive = "code"
-	} else {
-		expectedActive = "compiler"
-	}
+	fake := setup(t, target, fmt.Sprintf(`path: "%s"
+sessionName: "phoemux"
+defaultWindow: code
+windows:
+- name: code
+  terminals:
+  - command: nvim .
+- name: compiler
+  terminals:
+  - command: go build
+- name: elevated
+  terminals:
+  - command: sudo -s
+`, t.TempDir()))
+	expectedActive := "code"
 
 	expected := [3]string{
 		"code",
@@ -24,13 +32,24 @@ func TestRoot(t *testing.T) {
 		"elevated",
 	}
 
-	core.Open(phoemuxConfigPath, target)
+	err := core.Open(phoemuxConfigPath, target)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %s\n", target, err)
+	}
 
-	if !tmux.HasSession(target) {
+	if !fake.HasSession(target) {
 		t.Fatalf("Failed to created the session\n")
 	}
 
-	actualWindows, actualActive := tmux.GetListOfWindows(target)
+	session := fake.Sessions[target]
+	actualWindows := []string{}
+	for _, window := range session.Windows {
+		actualWindows = append(actualWindows, window.Name)
+	}
+
+	if len(actualWindows) != len(expected) {
+		t.Fatalf("Failed to created windows \nexpected %#v\nactual %#v\n", expected, actualWindows)
+	}
 
 	for i, v := range expected {
 		if v != actualWindows[i] {
@@ -38,11 +57,11 @@ func TestRoot(t *testing.T) {
 		}
 	}
 
-	if actualActive != expectedActive {
-		t.Fatalf("Wrong active window \nexpected %s actual %s\n", expectedActive, actualActive)
+	if session.ActiveWindow != expectedActive {
+		t.Fatalf("Wrong active window \nexpected %s actual %s\n", expectedActive, session.ActiveWindow)
 	}
 
-	if shouldKill {
-		tmux.Kill(target)
+	if fake.Current != target {
+		t.Fatalf("Did not attach to %s, attached to %s\n", target, fake.Current)
 	}
 }
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhonnyV-V/phoemux/core"
+	"github.com/jhonnyV-V/phoemux/tmux/tmuxtest"
+)
+
+func TestMain(m *testing.M) {
+	//before tests
+	tmpDir, err := os.MkdirTemp("", "phoemux-test")
+	if err != nil {
+		fmt.Printf("failed to create temporary directory with error %s\n", err)
+		os.Exit(1)
+	}
+	// os.UserConfigDir uses XDG_CONFIG_HOME on linux and HOME on macos
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+	os.Setenv("HOME", tmpDir)
+	core.CreateConfigDir()
+
+	exitVal := m.Run()
+	//cleanup
+	err = os.RemoveAll(tmpDir)
+	if err != nil {
+		fmt.Printf("failed remove test config directory with error %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(exitVal)
+}
+
+// setup writes the ash fixture into the config directory and replaces tmux with a fake
+func setup(t *testing.T, alias, ash string) *tmuxtest.Fake {
+	t.Helper()
+	filePath := filepath.Join(core.GetConfigPath(), alias+".yaml")
+	err := os.WriteFile(filePath, []byte(ash), 0666)
+	if err != nil {
+		t.Fatalf("failed to write ash %s: %s", alias, err)
+	}
+
+	fake := tmuxtest.New()
+	oldBackend := core.Backend
+	core.Backend = fake
+	t.Cleanup(func() {
+		core.Backend = oldBackend
+		os.Remove(filePath)
+	})
+	return fake
+}
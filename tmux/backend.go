@@ -0,0 +1,88 @@
+package tmux
+
+// Backend is the set of tmux operations used to build, open and kill sessions
+type Backend interface {
+	HasSession(sessionName string) bool
+	//CurrentSession returns the session phoemux runs in, empty outside of tmux
+	CurrentSession() string
+	//ListSessions returns every running session, including the ones phoemux did not create
+	ListSessions() []string
+	NewSession(ash Ash) error
+	NewWindow(ash Ash, window Window) error
+	SplitWindow(ash Ash, window Window, terminal Terminal) error
+	RespawnPane(ash Ash, window Window, terminal Terminal) error
+	SelectLayout(ash Ash, window Window) error
+	RunCommand(sessionName, currentWindow, command string) error
+	SetWindows(ash Ash) error
+	ChangeSession(ash Ash) error
+	Kill(sessionName string) error
+	RenameSession(sessionName, newName string) error
+	DescribeSession(sessionName string) ([]WindowInfo, error)
+	CapturePane(sessionName string) (string, error)
+}
+
+// Tmux is the Backend that runs the tmux binary
+type Tmux struct{}
+
+func (Tmux) HasSession(sessionName string) bool {
+	return HasSession(sessionName)
+}
+
+func (Tmux) CurrentSession() string {
+	if !IsInsideTmux() {
+		return ""
+	}
+	return GetCurrentSessionName()
+}
+
+func (Tmux) ListSessions() []string {
+	return GetListOfSessions()
+}
+
+func (Tmux) NewSession(ash Ash) error {
+	return NewSession(ash)
+}
+
+func (Tmux) NewWindow(ash Ash, window Window) error {
+	return NewWindow(ash, window)
+}
+
+func (Tmux) SplitWindow(ash Ash, window Window, terminal Terminal) error {
+	return SplitWindow(ash, window, terminal)
+}
+
+func (Tmux) RespawnPane(ash Ash, window Window, terminal Terminal) error {
+	return RespawnPane(ash, window, terminal)
+}
+
+func (Tmux) SelectLayout(ash Ash, window Window) error {
+	return SelectLayout(ash, window)
+}
+
+func (Tmux) RunCommand(sessionName, currentWindow, command string) error {
+	return RunCommand(sessionName, currentWindow, command)
+}
+
+func (Tmux) SetWindows(ash Ash) error {
+	return SetWindows(ash)
+}
+
+func (Tmux) ChangeSession(ash Ash) error {
+	return ChangeSession(ash)
+}
+
+func (Tmux) Kill(sessionName string) error {
+	return Kill(sessionName)
+}
+
+func (Tmux) RenameSession(sessionName, newName string) error {
+	return RenameSession(sessionName, newName)
+}
+
+func (Tmux) DescribeSession(sessionName string) ([]WindowInfo, error) {
+	return DescribeSession(sessionName)
+}
+
+func (Tmux) CapturePane(sessionName string) (string, error) {
+	return CapturePane(sessionName)
+}
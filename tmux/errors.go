@@ -0,0 +1,70 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	ErrTmuxNotInstalled = errors.New("tmux is not installed")
+	ErrServerNotRunning = errors.New("tmux server is not running")
+	ErrSessionExists    = errors.New("session already exists")
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrWindowNotFound   = errors.New("window not found")
+	ErrPaneNotFound     = errors.New("pane not found")
+)
+
+// CommandError is returned when a tmux command fails, it unwraps to one of
+// the Err values above when the failure is recognized
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	reason := e.Stderr
+	if reason == "" {
+		reason = e.Err.Error()
+	}
+	return fmt.Sprintf("tmux %s: %s", e.Args[0], reason)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+func newCommandError(args []string, stderr string, err error) *CommandError {
+	stderr = strings.TrimSpace(stderr)
+	if errors.Is(err, exec.ErrNotFound) {
+		err = ErrTmuxNotInstalled
+	} else if classified := classify(stderr); classified != nil {
+		err = classified
+	}
+
+	return &CommandError{
+		Args:   args,
+		Stderr: stderr,
+		Err:    err,
+	}
+}
+
+func classify(stderr string) error {
+	switch {
+	case strings.Contains(stderr, "duplicate session"):
+		return ErrSessionExists
+	case strings.Contains(stderr, "can't find session"),
+		strings.Contains(stderr, "session not found"):
+		return ErrSessionNotFound
+	case strings.Contains(stderr, "can't find window"):
+		return ErrWindowNotFound
+	case strings.Contains(stderr, "can't find pane"):
+		return ErrPaneNotFound
+	case strings.Contains(stderr, "no server running"),
+		strings.Contains(stderr, "error connecting to"):
+		return ErrServerNotRunning
+	}
+	return nil
+}
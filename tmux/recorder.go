@@ -0,0 +1,98 @@
+package tmux
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Recorder is a Backend that records the tmux commands it is asked to run instead of running them
+type Recorder struct {
+	//arguments of every tmux command, in order
+	Commands [][]string
+	//sessions reported as running by HasSession
+	Existing []string
+}
+
+var _ Backend = (*Recorder)(nil)
+
+func (r *Recorder) record(args []string) error {
+	r.Commands = append(r.Commands, args)
+	return nil
+}
+
+func (r *Recorder) HasSession(sessionName string) bool {
+	return slices.Contains(r.Existing, sessionName)
+}
+
+// CurrentSession is empty since nothing runs while recording
+func (r *Recorder) CurrentSession() string {
+	return ""
+}
+
+func (r *Recorder) ListSessions() []string {
+	return slices.Clone(r.Existing)
+}
+
+func (r *Recorder) NewSession(ash Ash) error {
+	r.Existing = append(r.Existing, ash.SessionName)
+	return r.record(newSessionArgs(ash))
+}
+
+func (r *Recorder) NewWindow(ash Ash, window Window) error {
+	return r.record(newWindowArgs(ash, window))
+}
+
+func (r *Recorder) SplitWindow(ash Ash, window Window, terminal Terminal) error {
+	return r.record(splitWindowArgs(ash, window, terminal))
+}
+
+func (r *Recorder) RespawnPane(ash Ash, window Window, terminal Terminal) error {
+	return r.record(respawnPaneArgs(ash, window, terminal))
+}
+
+func (r *Recorder) SelectLayout(ash Ash, window Window) error {
+	return r.record(selectLayoutArgs(ash, window))
+}
+
+func (r *Recorder) RunCommand(sessionName, currentWindow, command string) error {
+	return r.record(runCommandArgs(sessionName, currentWindow, command))
+}
+
+func (r *Recorder) SetWindows(ash Ash) error {
+	return r.record(setWindowsArgs(ash))
+}
+
+// ChangeSession records switch-client or attach-session depending on
+// where phoemux is running, like ChangeSession does
+func (r *Recorder) ChangeSession(ash Ash) error {
+	if IsInsideTmux() {
+		return r.record(SwitchSessionArgs(ash.SessionName))
+	}
+	return r.record(AttachArgs(ash))
+}
+
+// Kill records the kill-session command, the processes of the
+// session are asked to quit before it when Kill runs
+func (r *Recorder) Kill(sessionName string) error {
+	r.Existing = slices.DeleteFunc(r.Existing, func(s string) bool {
+		return s == sessionName
+	})
+	return r.record(killSessionArgs(sessionName))
+}
+
+func (r *Recorder) RenameSession(sessionName, newName string) error {
+	for i, existing := range r.Existing {
+		if existing == sessionName {
+			r.Existing[i] = newName
+		}
+	}
+	return r.record(renameSessionArgs(sessionName, newName))
+}
+
+func (r *Recorder) DescribeSession(sessionName string) ([]WindowInfo, error) {
+	return nil, fmt.Errorf("can not describe session %s while recording", sessionName)
+}
+
+func (r *Recorder) CapturePane(sessionName string) (string, error) {
+	return "", fmt.Errorf("can not capture session %s while recording", sessionName)
+}
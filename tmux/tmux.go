@@ -1,173 +1,345 @@
 package tmux
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strings"
 )
 
 type Terminal struct {
-	Command string `yaml:"command"`
+	Command string `yaml:"command,omitempty"`
+	//directory of the pane, relative paths are resolved against the ash path
+	Path string `yaml:"path,omitempty"`
+	//size of the pane created for this terminal, in cells or a percentage like 30%
+	Size string            `yaml:"size,omitempty"`
+	Env  map[string]string `yaml:"env,omitempty"`
 }
 
 type Window struct {
+	Name string `yaml:"name"`
 	//values: horizontal or vertical
-	Split     string     `yaml:"split,omitempty"`
-	Name      string     `yaml:"name"`
-	Terminals []Terminal `yaml:"terminals"`
+	Split string `yaml:"split,omitempty"`
+	//values: even-horizontal, even-vertical, main-horizontal, main-vertical,
+	//tiled or a custom layout string as printed by list-windows
+	Layout string `yaml:"layout,omitempty"`
+	//directory of the window, relative paths are resolved against the ash path
+	Path      string            `yaml:"path,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	Terminals []Terminal        `yaml:"terminals"`
+}
+
+// Hooks are commands run on the host, outside of tmux, from the ash path
+type Hooks struct {
+	//run after the session is created
+	OnCreate []string `yaml:"onCreate,omitempty"`
+	//run every time an already existing session is attached
+	OnAttach []string `yaml:"onAttach,omitempty"`
+	//run before the session is killed
+	OnKill []string `yaml:"onKill,omitempty"`
 }
 
 type Ash struct {
-	Path          string   `yaml:"path"`
-	SessionName   string   `yaml:"sessionName"`
-	DefaultWindow string   `yaml:"defaultWindow"`
-	Windows       []Window `yaml:"windows"`
+	//alias of the ash this one inherits its env, hooks and windows from
+	Extends     string `yaml:"extends,omitempty"`
+	Path        string `yaml:"path"`
+	SessionName string `yaml:"sessionName"`
+	//values for ${name} and {{ .name }} in the paths, the session name and the commands
+	Vars map[string]string `yaml:"vars,omitempty"`
+	//runs the paths, the session name and the commands as go templates, so {{ is left alone by default
+	Template bool `yaml:"template,omitempty"`
+	//environment of the session, windows and terminals inherit and may override it
+	Env           map[string]string `yaml:"env,omitempty"`
+	Hooks         Hooks             `yaml:"hooks,omitempty"`
+	DefaultWindow string            `yaml:"defaultWindow"`
+	Windows       []Window          `yaml:"windows"`
 }
 
-func NewSession(ash Ash) {
-	cmd := exec.Command(
-		"tmux",
-		"new-session",
-		"-s", ash.SessionName,
-		"-d",
-		"-c",
-		ash.Path,
-	)
-	fmt.Println(cmd.Args)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+func resolvePath(base, path string) string {
+	if path == "" {
+		return base
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// WindowPath returns the directory where the window starts
+func WindowPath(ash Ash, window Window) string {
+	return resolvePath(ash.Path, window.Path)
+}
+
+// TerminalPath returns the directory where the pane of the terminal starts,
+// falling back to the window directory
+func TerminalPath(ash Ash, window Window, terminal Terminal) string {
+	if terminal.Path == "" {
+		return WindowPath(ash, window)
+	}
+	return resolvePath(ash.Path, terminal.Path)
+}
+
+// firstPanePath returns the directory of the pane created along with the window
+func firstPanePath(ash Ash, window Window) string {
+	if len(window.Terminals) == 0 {
+		return WindowPath(ash, window)
+	}
+	return TerminalPath(ash, window, window.Terminals[0])
+}
+
+// PaneEnv returns the variables the terminal sets on top of the session environment,
+// the terminal overrides the window
+func PaneEnv(window Window, terminal Terminal) map[string]string {
+	env := map[string]string{}
+	maps.Copy(env, window.Env)
+	maps.Copy(env, terminal.Env)
+	return env
+}
+
+func firstPaneEnv(window Window) map[string]string {
+	if len(window.Terminals) == 0 {
+		return PaneEnv(window, Terminal{})
+	}
+	return PaneEnv(window, window.Terminals[0])
+}
+
+func envArgs(env map[string]string) []string {
+	args := []string{}
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, env[key]))
+	}
+	return args
+}
+
+// run executes a tmux command, the output of tmux is kept to build the error
+func run(args ...string) error {
+	_, err := output(args...)
+	return err
+}
+
+// output executes a tmux command and returns what it printed
+func output(args ...string) (string, error) {
+	trace(args)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("tmux", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 	err := cmd.Run()
 	if err != nil {
-		fmt.Printf("failed to open session: %s\n", err)
+		return "", newCommandError(args, stderr.String(), err)
 	}
+	return stdout.String(), nil
 }
 
-func RenameWindow(ash Ash, oldName, newName string) {
-	target := fmt.Sprintf("%s:%s", ash.SessionName, oldName)
-	cmd := exec.Command(
-		"tmux",
-		"rename-window",
-		fmt.Sprintf("-t=%s", target),
-		newName,
-	)
+// runInteractive executes a tmux command that takes over the terminal
+func runInteractive(args ...string) error {
+	trace(args)
+	var stderr bytes.Buffer
+	cmd := exec.Command("tmux", args...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 	cmd.Stdin = os.Stdin
 	err := cmd.Run()
 	if err != nil {
-		fmt.Printf("failed to rename window: %s\n", err)
+		return newCommandError(args, stderr.String(), err)
 	}
+	return nil
 }
 
-func NewWindow(ash Ash, window Window) {
-	cmd := exec.Command(
-		"tmux",
-		"new-window",
+// The *Args functions build the arguments of each tmux command so the
+// commands that are run and the ones that are recorded are the same
+
+func newSessionArgs(ash Ash) []string {
+	args := []string{
+		"new-session",
+		"-s", ash.SessionName,
+		"-d",
 		"-c",
 		ash.Path,
+	}
+	if len(ash.Windows) > 0 {
+		args[len(args)-1] = firstPanePath(ash, ash.Windows[0])
+		args = append(args, "-n", ash.Windows[0].Name)
+	}
+	return append(args, envArgs(ash.Env)...)
+}
+
+func newWindowArgs(ash Ash, window Window) []string {
+	args := []string{
+		"new-window",
+		"-c",
+		firstPanePath(ash, window),
 		"-n",
 		window.Name,
 		fmt.Sprintf("-t=%s", ash.SessionName),
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("failed to create window %s\n", err)
 	}
+	return append(args, envArgs(firstPaneEnv(window))...)
+}
+
+func splitWindowArgs(ash Ash, window Window, terminal Terminal) []string {
+	direction := "-v"
+	if window.Split == "horizontal" {
+		direction = "-h"
+	}
+
+	target := fmt.Sprintf("%s:%s", ash.SessionName, window.Name)
+	args := []string{
+		"split-window",
+		direction,
+		"-c",
+		TerminalPath(ash, window, terminal),
+		fmt.Sprintf("-t=%s", target),
+	}
+	if terminal.Size != "" {
+		args = append(args, "-l", terminal.Size)
+	}
+	return append(args, envArgs(PaneEnv(window, terminal))...)
+}
+
+func respawnPaneArgs(ash Ash, window Window, terminal Terminal) []string {
+	target := fmt.Sprintf("%s:%s", ash.SessionName, window.Name)
+	args := []string{
+		"respawn-pane",
+		"-k",
+		"-c",
+		TerminalPath(ash, window, terminal),
+		fmt.Sprintf("-t=%s", target),
+	}
+	return append(args, envArgs(PaneEnv(window, terminal))...)
 }
 
-func RunCommand(sessionName, currentWindow, command string) {
-	target := fmt.Sprintf("%s:%s.0", sessionName, currentWindow)
-	cmd := exec.Command(
-		"tmux",
+func selectLayoutArgs(ash Ash, window Window) []string {
+	target := fmt.Sprintf("%s:%s", ash.SessionName, window.Name)
+	return []string{
+		"select-layout",
+		fmt.Sprintf("-t=%s", target),
+		window.Layout,
+	}
+}
+
+func runCommandArgs(sessionName, currentWindow, command string) []string {
+	target := fmt.Sprintf("%s:%s", sessionName, currentWindow)
+	return []string{
 		"send-keys",
 		fmt.Sprintf("-t=%s", target),
 		command,
 		"C-m",
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("failed to run command %s: %s\n", command, err)
 	}
 }
 
-func SetWindows(ash Ash) {
+func setWindowsArgs(ash Ash) []string {
 	target := fmt.Sprintf("%s:%s", ash.SessionName, ash.DefaultWindow)
-	cmd := exec.Command(
-		"tmux",
+	return []string{
 		"select-window",
 		fmt.Sprintf("-t=%s", target),
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("failed to select window: %s\n", err)
 	}
 }
 
-func switchSession(sessionName string) {
-	cmd := exec.Command(
-		"tmux",
+// HasSessionArgs checks if the session exists, tmux exits with 1 when it does not
+func HasSessionArgs(sessionName string) []string {
+	return []string{
+		"has-session",
+		fmt.Sprintf("-t=%s", sessionName),
+	}
+}
+
+// SwitchSessionArgs moves the current client, from inside tmux, to the session
+func SwitchSessionArgs(sessionName string) []string {
+	return []string{
 		"switch-client",
 		fmt.Sprintf("-t=%s", sessionName),
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("failed to switch to session: %s\n", err)
 	}
 }
 
-func Attach(ash Ash) {
-	var cmd *exec.Cmd
+// AttachArgs attaches the terminal, from outside tmux, to the session
+func AttachArgs(ash Ash) []string {
 	if ash.SessionName != "" {
-		cmd = exec.Command(
-			"tmux",
+		return []string{
 			"attach-session",
 			fmt.Sprintf("-t=%s", ash.SessionName),
-		)
-	} else {
-		cmd = exec.Command(
-			"tmux",
-			"attach-session",
-		)
+		}
 	}
+	return []string{"attach-session"}
+}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("failed to attach session: %s\n", err)
+func killSessionArgs(sessionName string) []string {
+	return []string{
+		"kill-session",
+		"-t", sessionName,
 	}
 }
 
-func HasSession(sessionName string) bool {
-	cmd := exec.Command(
-		"tmux",
-		"has-session",
+func renameSessionArgs(sessionName, newName string) []string {
+	return []string{
+		"rename-session",
 		fmt.Sprintf("-t=%s", sessionName),
-	)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
-	if err != nil || cmd.ProcessState.ExitCode() != 0 {
-		return false
+		newName,
 	}
-	return true
+}
+
+// NewSession creates the session along with its first window and the ash environment,
+// which is inherited by every pane of the session
+func NewSession(ash Ash) error {
+	return run(newSessionArgs(ash)...)
+}
+
+func RenameWindow(ash Ash, oldName, newName string) error {
+	target := fmt.Sprintf("%s:%s", ash.SessionName, oldName)
+	return run(
+		"rename-window",
+		fmt.Sprintf("-t=%s", target),
+		newName,
+	)
+}
+
+func NewWindow(ash Ash, window Window) error {
+	return run(newWindowArgs(ash, window)...)
+}
+
+// SplitWindow creates a new pane for the terminal following the window Split direction,
+// horizontal puts the panes side by side and vertical (the default) stacks them.
+// The new pane becomes the active one.
+func SplitWindow(ash Ash, window Window, terminal Terminal) error {
+	return run(splitWindowArgs(ash, window, terminal)...)
+}
+
+// RespawnPane restarts the active pane of the window so it picks up the
+// terminal environment, used for the pane created along with the session
+func RespawnPane(ash Ash, window Window, terminal Terminal) error {
+	return run(respawnPaneArgs(ash, window, terminal)...)
+}
+
+// SelectLayout arranges the panes of the window using its Layout
+func SelectLayout(ash Ash, window Window) error {
+	return run(selectLayoutArgs(ash, window)...)
+}
+
+// RunCommand sends the command to the active pane of the window
+func RunCommand(sessionName, currentWindow, command string) error {
+	return run(runCommandArgs(sessionName, currentWindow, command)...)
+}
+
+func SetWindows(ash Ash) error {
+	return run(setWindowsArgs(ash)...)
+}
+
+func switchSession(sessionName string) error {
+	return run(SwitchSessionArgs(sessionName)...)
+}
+
+func Attach(ash Ash) error {
+	return runInteractive(AttachArgs(ash)...)
+}
+
+func HasSession(sessionName string) bool {
+	err := run(HasSessionArgs(sessionName)...)
+	return err == nil
 }
 
 func IsInsideTmux() bool {
@@ -175,44 +347,37 @@ func IsInsideTmux() bool {
 	return tmuxEnvExist
 }
 
-func ChangeSession(ash Ash) {
+func ChangeSession(ash Ash) error {
 	tmuxEnvExist := IsInsideTmux()
 	if tmuxEnvExist {
-		switchSession(ash.SessionName)
-		return
+		return switchSession(ash.SessionName)
 	}
-	Attach(ash)
+	return Attach(ash)
 }
 
 func GetCurrentSessionName() string {
-	cmd := exec.Command(
-		"tmux",
+	out, err := output(
 		"display-message",
 		"-p",
 		"#S",
 	)
-
-	out, err := cmd.Output()
 	if err != nil {
 		return ""
 	}
-	return strings.ReplaceAll(string(out), "\n", "")
+	return strings.ReplaceAll(out, "\n", "")
 }
 
 func GetListOfSessions() []string {
 	sessions := []string{}
-	cmd := exec.Command(
-		"tmux",
+	out, err := output(
 		"list-sessions",
 		"-F",
 		"#{session_name}",
 	)
-
-	out, err := cmd.Output()
 	if err != nil {
 		return sessions
 	}
-	sessions = strings.Split(string(out), "\n")
+	sessions = strings.Split(out, "\n")
 	return filter(sessions, func(s string) bool {
 		return s != ""
 	})
@@ -256,89 +421,192 @@ func GetOtherSession() string {
 	return sessions[0]
 }
 
+// listSession runs list-windows or list-panes -s on the session and returns the formats
+// of every line, lines without one value per format are skipped
+func listSession(command, sessionName string, formats ...string) ([][]string, error) {
+	args := []string{command}
+	if command == "list-panes" {
+		args = append(args, "-s")
+	}
+	args = append(args, fmt.Sprintf("-t=%s", sessionName), "-F", strings.Join(formats, "\t"))
+	out, err := output(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := [][]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if line == "" || len(fields) != len(formats) {
+			continue
+		}
+		lines = append(lines, fields)
+	}
+	return lines, nil
+}
+
 func GetListOfWindows(sessionName string) ([]string, string) {
 	windows := []string{}
 	active := ""
-	cmd := exec.Command(
-		"tmux",
-		"list-windows",
-		"-t", sessionName,
-		"-F",
-		"#{window_name}active=#{window_active}",
-	)
-
-	out, err := cmd.Output()
+	lines, err := listSession("list-windows", sessionName, "#{window_name}", "#{window_active}")
 	if err != nil {
 		return windows, active
 	}
-	windows = strings.Split(string(out), "\n")
 
-	windows = filter(windows, func(s string) bool {
-		return s != ""
-	})
-
-	for i, v := range windows {
-		result := strings.Split(v, "active=")
-		if result[1] == "1" {
-			active = result[0]
+	for _, fields := range lines {
+		if fields[1] == "1" {
+			active = fields[0]
 		}
-		windows[i] = result[0]
+		windows = append(windows, fields[0])
 	}
 	return windows, active
 }
 
-func GetListOfPanes(sessionName string) []string {
-	cmd := exec.Command(
-		"tmux",
-		"list-panes",
-		"-a",
-		"-F",
-		"#{pane_id} #{pane_current_command} #{session_name}",
+// CapturePane returns what the active pane of the session shows
+func CapturePane(sessionName string) (string, error) {
+	return output(
+		"capture-pane",
+		"-p",
+		// a pane target needs the colon for = to match the session exactly
+		fmt.Sprintf("-t=%s:", sessionName),
 	)
+}
 
-	out, err := cmd.Output()
-
+// GetListOfPanes returns the id, the command and the session of every pane separated by spaces
+func GetListOfPanes(sessionName string) ([]string, error) {
+	lines, err := listSession("list-panes", sessionName, "#{pane_id}", "#{pane_current_command}", "#{session_name}")
 	if err != nil {
-		fmt.Printf("Failed to get list of panes: %s\n", err)
-		return []string{}
+		return []string{}, err
 	}
 
-	panes := strings.Split(string(out), "\n")
-
-	panes = filter(panes, func(s string) bool {
-		if s == "" {
-			return false
-		}
+	panes := []string{}
+	for _, fields := range lines {
+		panes = append(panes, strings.Join(fields, " "))
+	}
+	return panes, nil
+}
 
-		return strings.Contains(s, sessionName)
-	})
+// PaneInfo describes a pane of a running session
+type PaneInfo struct {
+	Path string
+	//command running in the pane, empty when it is only a shell
+	Command string
+}
 
-	return panes
+// WindowInfo describes a window of a running session
+type WindowInfo struct {
+	Name   string
+	Layout string
+	Active bool
+	Panes  []PaneInfo
 }
 
-func SendCommandToPane(paneId string, commands []string) {
-	args := []string{"send-keys", "-t", paneId}
+var shells = []string{"bash", "zsh", "fish", "sh", "dash", "ksh", "tcsh"}
 
-	for _, command := range commands {
-		args = append(args, command)
+// processCommands returns the command line of every process
+// and the command line of one child of every process
+func processCommands() (map[string]string, map[string]string) {
+	commands := map[string]string{}
+	children := map[string]string{}
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		return commands, children
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		args := strings.Join(fields[2:], " ")
+		commands[fields[0]] = args
+		if _, exist := children[fields[1]]; !exist {
+			children[fields[1]] = args
+		}
 	}
-	cmd := exec.Command(
-		"tmux",
-		args...,
+	return commands, children
+}
+
+// DescribeSession lists the windows of the session with their layout and
+// the directory and command of each pane
+func DescribeSession(sessionName string) ([]WindowInfo, error) {
+	lines, err := listSession(
+		"list-windows",
+		sessionName,
+		"#{window_index}", "#{window_name}", "#{window_active}", "#{window_layout}",
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	err := cmd.Run()
+	windows := []WindowInfo{}
+	indexes := map[string]int{}
+	for _, fields := range lines {
+		indexes[fields[0]] = len(windows)
+		windows = append(windows, WindowInfo{
+			Name:   fields[1],
+			Active: fields[2] == "1",
+			Layout: fields[3],
+		})
+	}
+
+	lines, err = listSession(
+		"list-panes",
+		sessionName,
+		"#{window_index}", "#{pane_current_path}", "#{pane_current_command}", "#{pane_pid}",
+	)
 	if err != nil {
-		fmt.Printf("failed to send command to pane: %s\n", err)
+		return nil, err
 	}
+
+	commands, children := processCommands()
+	for _, fields := range lines {
+		i, ok := indexes[fields[0]]
+		if !ok {
+			continue
+		}
+
+		pane := PaneInfo{Path: fields[1]}
+		panePid := fields[3]
+		command, ok := commands[panePid]
+		if !ok {
+			command = fields[2]
+		}
+		// dead or respawning panes have no command
+		program := ""
+		if args := strings.Fields(command); len(args) > 0 {
+			program = strings.TrimPrefix(filepath.Base(args[0]), "-")
+		}
+		if slices.Contains(shells, program) {
+			// a shell is only waiting for input unless it started a command
+			command = children[panePid]
+		}
+		pane.Command = command
+		windows[i].Panes = append(windows[i].Panes, pane)
+	}
+
+	return windows, nil
+}
+
+func SendCommandToPane(paneId string, commands []string) error {
+	args := []string{"send-keys", "-t", paneId}
+	args = append(args, commands...)
+	return run(args...)
 }
 
-func killAllProceessInSession(sessionName string) {
-	panes := GetListOfPanes(sessionName)
+// killAllProceessInSession asks every process in the session to quit,
+// it is a best effort so panes that close while it runs are not an error
+func killAllProceessInSession(sessionName string) error {
+	panes, err := GetListOfPanes(sessionName)
+	if err != nil {
+		return err
+	}
 	slices.Reverse(panes)
 
 	for _, pane := range panes {
 		paneData := strings.Split(pane, " ")
+		if len(paneData) < 3 {
+			continue
+		}
 		paneId := paneData[0]
 		paneProc := strings.ToLower(paneData[1])
 		cmd := []string{}
@@ -379,25 +647,33 @@ func killAllProceessInSession(sessionName string) {
 			cmd = append(cmd, "C-c")
 		}
 
-		SendCommandToPane(paneId, cmd)
+		err = SendCommandToPane(paneId, cmd)
+		if err != nil && !errors.Is(err, ErrPaneNotFound) && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
 	}
+	return nil
 }
 
-func Kill(sessionName string) {
-
-	cmd := exec.Command(
-		"tmux",
-		"kill-session",
-		"-t", sessionName,
-	)
-
-	killAllProceessInSession(sessionName)
+func Kill(sessionName string) error {
+	err := run(HasSessionArgs(sessionName)...)
+	if err != nil {
+		return err
+	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	err := cmd.Run()
+	err = killAllProceessInSession(sessionName)
 	if err != nil {
-		fmt.Printf("failed to kill session: %s\n", err)
+		return err
+	}
+
+	err = run(killSessionArgs(sessionName)...)
+	// the session is already gone when all of its processes exited
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
 	}
+	return err
+}
+
+func RenameSession(sessionName, newName string) error {
+	return run(renameSessionArgs(sessionName, newName)...)
 }
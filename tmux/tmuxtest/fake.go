@@ -0,0 +1,298 @@
+// Package tmuxtest provides an in-memory tmux.Backend to test phoemux without a tmux server
+package tmuxtest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jhonnyV-V/phoemux/tmux"
+)
+
+type Pane struct {
+	Path string
+	Env  map[string]string
+	//every command sent to the pane, in order
+	Keys []string
+}
+
+type Window struct {
+	Name   string
+	Layout string
+	Panes  []*Pane
+}
+
+type Session struct {
+	Name         string
+	Env          map[string]string
+	Windows      []*Window
+	ActiveWindow string
+}
+
+// Fake records the sessions, windows and panes that would exist in tmux
+type Fake struct {
+	Sessions map[string]*Session
+	//session the client is attached to
+	Current string
+	//sessions killed, in order
+	Killed []string
+	//error returned by an operation instead of running it, keyed by the method name
+	Errors map[string]error
+}
+
+var _ tmux.Backend = (*Fake)(nil)
+
+func New() *Fake {
+	return &Fake{
+		Sessions: map[string]*Session{},
+		Errors:   map[string]error{},
+	}
+}
+
+func (f *Fake) session(sessionName string) (*Session, error) {
+	session, ok := f.Sessions[sessionName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", tmux.ErrSessionNotFound, sessionName)
+	}
+	return session, nil
+}
+
+func (f *Fake) window(sessionName, windowName string) (*Window, error) {
+	session, err := f.session(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	for _, window := range session.Windows {
+		if window.Name == windowName {
+			return window, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s:%s", tmux.ErrWindowNotFound, sessionName, windowName)
+}
+
+func newPane(ash tmux.Ash, window tmux.Window, terminal tmux.Terminal) *Pane {
+	return &Pane{
+		Path: tmux.TerminalPath(ash, window, terminal),
+		Env:  tmux.PaneEnv(window, terminal),
+	}
+}
+
+func newWindow(ash tmux.Ash, window tmux.Window) *Window {
+	terminal := tmux.Terminal{}
+	if len(window.Terminals) > 0 {
+		terminal = window.Terminals[0]
+	}
+	return &Window{
+		Name:  window.Name,
+		Panes: []*Pane{newPane(ash, window, terminal)},
+	}
+}
+
+func (f *Fake) HasSession(sessionName string) bool {
+	_, ok := f.Sessions[sessionName]
+	return ok
+}
+
+// CurrentSession is the session the fake last switched to
+func (f *Fake) CurrentSession() string {
+	return f.Current
+}
+
+// ListSessions returns the names of the sessions in order
+func (f *Fake) ListSessions() []string {
+	return slices.Sorted(maps.Keys(f.Sessions))
+}
+
+func (f *Fake) NewSession(ash tmux.Ash) error {
+	if err := f.Errors["NewSession"]; err != nil {
+		return err
+	}
+	if f.HasSession(ash.SessionName) {
+		return fmt.Errorf("%w: %s", tmux.ErrSessionExists, ash.SessionName)
+	}
+	session := &Session{
+		Name: ash.SessionName,
+		Env:  maps.Clone(ash.Env),
+	}
+	if len(ash.Windows) > 0 {
+		session.Windows = append(session.Windows, newWindow(ash, ash.Windows[0]))
+		session.ActiveWindow = ash.Windows[0].Name
+	}
+	f.Sessions[ash.SessionName] = session
+	return nil
+}
+
+func (f *Fake) NewWindow(ash tmux.Ash, window tmux.Window) error {
+	if err := f.Errors["NewWindow"]; err != nil {
+		return err
+	}
+	session, err := f.session(ash.SessionName)
+	if err != nil {
+		return err
+	}
+	session.Windows = append(session.Windows, newWindow(ash, window))
+	session.ActiveWindow = window.Name
+	return nil
+}
+
+func (f *Fake) SplitWindow(ash tmux.Ash, window tmux.Window, terminal tmux.Terminal) error {
+	if err := f.Errors["SplitWindow"]; err != nil {
+		return err
+	}
+	w, err := f.window(ash.SessionName, window.Name)
+	if err != nil {
+		return err
+	}
+	w.Panes = append(w.Panes, newPane(ash, window, terminal))
+	return nil
+}
+
+func (f *Fake) RespawnPane(ash tmux.Ash, window tmux.Window, terminal tmux.Terminal) error {
+	if err := f.Errors["RespawnPane"]; err != nil {
+		return err
+	}
+	w, err := f.window(ash.SessionName, window.Name)
+	if err != nil {
+		return err
+	}
+	w.Panes[len(w.Panes)-1] = newPane(ash, window, terminal)
+	return nil
+}
+
+func (f *Fake) SelectLayout(ash tmux.Ash, window tmux.Window) error {
+	if err := f.Errors["SelectLayout"]; err != nil {
+		return err
+	}
+	w, err := f.window(ash.SessionName, window.Name)
+	if err != nil {
+		return err
+	}
+	w.Layout = window.Layout
+	return nil
+}
+
+// RunCommand records the command in the last pane of the window,
+// which is the active one while the window is being built
+func (f *Fake) RunCommand(sessionName, currentWindow, command string) error {
+	if err := f.Errors["RunCommand"]; err != nil {
+		return err
+	}
+	w, err := f.window(sessionName, currentWindow)
+	if err != nil {
+		return err
+	}
+	pane := w.Panes[len(w.Panes)-1]
+	pane.Keys = append(pane.Keys, command)
+	return nil
+}
+
+func (f *Fake) SetWindows(ash tmux.Ash) error {
+	if err := f.Errors["SetWindows"]; err != nil {
+		return err
+	}
+	_, err := f.window(ash.SessionName, ash.DefaultWindow)
+	if err != nil {
+		return err
+	}
+	f.Sessions[ash.SessionName].ActiveWindow = ash.DefaultWindow
+	return nil
+}
+
+func (f *Fake) ChangeSession(ash tmux.Ash) error {
+	if err := f.Errors["ChangeSession"]; err != nil {
+		return err
+	}
+	_, err := f.session(ash.SessionName)
+	if err != nil {
+		return err
+	}
+	f.Current = ash.SessionName
+	return nil
+}
+
+func (f *Fake) Kill(sessionName string) error {
+	if err := f.Errors["Kill"]; err != nil {
+		return err
+	}
+	_, err := f.session(sessionName)
+	if err != nil {
+		return err
+	}
+	delete(f.Sessions, sessionName)
+	f.Killed = append(f.Killed, sessionName)
+	if f.Current == sessionName {
+		f.Current = ""
+	}
+	return nil
+}
+
+func (f *Fake) RenameSession(sessionName, newName string) error {
+	if err := f.Errors["RenameSession"]; err != nil {
+		return err
+	}
+	session, err := f.session(sessionName)
+	if err != nil {
+		return err
+	}
+	if _, exists := f.Sessions[newName]; exists {
+		return tmux.ErrSessionExists
+	}
+	delete(f.Sessions, sessionName)
+	session.Name = newName
+	f.Sessions[newName] = session
+	if f.Current == sessionName {
+		f.Current = newName
+	}
+	return nil
+}
+
+// DescribeSession reports the last command sent to each pane as the one running in it
+func (f *Fake) DescribeSession(sessionName string) ([]tmux.WindowInfo, error) {
+	if err := f.Errors["DescribeSession"]; err != nil {
+		return nil, err
+	}
+	session, err := f.session(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := []tmux.WindowInfo{}
+	for _, window := range session.Windows {
+		info := tmux.WindowInfo{
+			Name:   window.Name,
+			Layout: window.Layout,
+			Active: window.Name == session.ActiveWindow,
+		}
+		for _, pane := range window.Panes {
+			command := ""
+			if len(pane.Keys) > 0 {
+				command = pane.Keys[len(pane.Keys)-1]
+			}
+			info.Panes = append(info.Panes, tmux.PaneInfo{
+				Path:    pane.Path,
+				Command: command,
+			})
+		}
+		windows = append(windows, info)
+	}
+	return windows, nil
+}
+
+// CapturePane shows the commands sent to the last pane of the active window, one per line
+func (f *Fake) CapturePane(sessionName string) (string, error) {
+	if err := f.Errors["CapturePane"]; err != nil {
+		return "", err
+	}
+	session, err := f.session(sessionName)
+	if err != nil {
+		return "", err
+	}
+	for _, window := range session.Windows {
+		if window.Name != session.ActiveWindow || len(window.Panes) == 0 {
+			continue
+		}
+		return strings.Join(window.Panes[len(window.Panes)-1].Keys, "\n") + "\n", nil
+	}
+	return "", nil
+}
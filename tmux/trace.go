@@ -0,0 +1,39 @@
+package tmux
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Trace receives every tmux command before it runs, nothing is traced when it is nil
+	Trace io.Writer
+
+	safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+)
+
+// Quote makes the value a single word for a POSIX shell
+func Quote(value string) string {
+	if safeShellWord.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// CommandLine returns the tmux command as it would be typed in a shell
+func CommandLine(args []string) string {
+	words := []string{"tmux"}
+	for _, arg := range args {
+		words = append(words, Quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+func trace(args []string) {
+	if Trace == nil {
+		return
+	}
+	fmt.Fprintln(Trace, CommandLine(args))
+}